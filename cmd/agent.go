@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/WillCMcC/ws/internal/config"
+	"github.com/WillCMcC/ws/internal/fsutil"
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+// handoffEnv names the file the shell integration passes to 'ws ez' and
+// 'ws agent'. The first line written to it is the workspace to cd into;
+// anything after is the agent command for the shell to run there.
+const handoffEnv = "WS_HANDOFF"
+
+// AgentCmd handles the 'ws agent' command.
+func AgentCmd(args []string) int {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	launch := fs.String("launch", "", "How to start the agent: auto, shell or native (default from agent_launch)")
+	prompt := fs.String("prompt", "", "Task for the agent ('-' reads it from stdin)")
+	promptFile := fs.String("prompt-file", "", "Read the task for the agent from a file ('-' for stdin)")
+	savedPrompt := fs.Bool("saved-prompt", false, "Give the agent the task it was last started with")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ws agent [--launch <mode>] [--prompt <text> | --prompt-file <file> | --saved-prompt] [name]\n\n")
+		fmt.Fprintf(os.Stderr, "Start your agent in a workspace.\n\n")
+		fmt.Fprintf(os.Stderr, "If no name is given, uses the current workspace.\n\n")
+		fmt.Fprintf(os.Stderr, "Launch modes:\n")
+		fmt.Fprintf(os.Stderr, "  shell   The shell integration runs the agent in your shell\n")
+		fmt.Fprintf(os.Stderr, "  native  ws runs the agent itself, so no shell integration is needed\n")
+		fmt.Fprintf(os.Stderr, "  auto    shell when run through the shell integration, native otherwise\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	// Accept flags after the name too, as in 'ws agent <name> --prompt ...'
+	name := fs.Arg(0)
+	if name != "" {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+		if fs.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "ws: unexpected argument '%s'\n", fs.Arg(0))
+			return 1
+		}
+	}
+
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if err.Error() == "not a git repository" {
+			fmt.Fprintf(os.Stderr, "    Run this command from within a git repository.\n")
+			return 2
+		}
+		return 1
+	}
+
+	mode, code := launchMode(mgr, *launch)
+	if code != 0 {
+		return code
+	}
+	if err := mgr.CheckAgentTrust(); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
+	var ws *workspace.Workspace
+	if name != "" {
+		if ws, code = resolveWorkspace(mgr, name); code != 0 {
+			return code
+		}
+	} else {
+		ws, err = mgr.Current()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+			fmt.Fprintf(os.Stderr, "    Run from within a workspace, or specify: ws agent <name>\n")
+			return 1
+		}
+	}
+
+	task, err := readPrompt(*prompt, *promptFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+	if *savedPrompt {
+		if task != "" {
+			fmt.Fprintf(os.Stderr, "ws: --saved-prompt can't be combined with a new prompt\n")
+			return 1
+		}
+		if task = ws.Meta.Prompt; task == "" {
+			fmt.Fprintf(os.Stderr, "ws: no prompt saved for '%s'\n", ws.Name)
+			return 1
+		}
+	}
+
+	return startAgent(mgr, ws, mode, task)
+}
+
+// launchMode decides how to start the agent from the --launch flag, or
+// agent_launch when it's empty: shell or native. A nonzero code means the
+// mode can't be used here, and has been reported.
+func launchMode(mgr *workspace.Manager, launch string) (string, int) {
+	if launch == "" {
+		launch = mgr.Config.Agent.Launch
+	}
+	handoff := os.Getenv(handoffEnv)
+
+	switch launch {
+	case config.LaunchAuto:
+		if handoff != "" {
+			return config.LaunchShell, 0
+		}
+		return config.LaunchNative, 0
+	case config.LaunchShell:
+		if handoff == "" {
+			fmt.Fprintf(os.Stderr, "ws: the shell launch mode needs the shell integration\n")
+			fmt.Fprintf(os.Stderr, "    Run 'ws init' to set it up, or use --launch native.\n")
+			return "", 1
+		}
+		return launch, 0
+	case config.LaunchNative:
+		return launch, 0
+	default:
+		fmt.Fprintf(os.Stderr, "ws: unknown launch mode '%s' (expected auto, shell or native)\n", launch)
+		return "", 1
+	}
+}
+
+// startAgent runs the pre-agent hook and starts the configured agent in ws
+// on the task in prompt, if any, either handing it to the shell integration
+// or running it natively, as launchMode decided. With a native launch it
+// returns once the agent has exited, with its exit code.
+func startAgent(mgr *workspace.Manager, ws *workspace.Workspace, launch, prompt string) int {
+	handoff := os.Getenv(handoffEnv)
+
+	if err := mgr.RunHook(workspace.EventPreAgent, ws.Name, ws.Path); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
+	command := mgr.Config.Agent.Cmd
+	if prompt != "" {
+		command = promptCommand(mgr.Config.Agent.PromptTemplate, command, prompt)
+	}
+	if err := mgr.RecordAgent(ws.Name, command, prompt); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save workspace metadata: %v\n", err)
+	}
+
+	if launch == config.LaunchShell {
+		// The shell integration runs the agent, then the post-agent hook
+		return writeHandoff(handoff, ws.Path, command)
+	}
+
+	code, err := mgr.RunAgent(ws, command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+	// Still tell the shell integration where to cd
+	if handoff != "" {
+		writeHandoff(handoff, ws.Path, "")
+	}
+	return code
+}
+
+// writeHandoff writes the workspace path and the agent command, if any,
+// for the shell integration.
+func writeHandoff(path, wsPath, command string) int {
+	data := wsPath + "\n"
+	if command != "" {
+		data += command + "\n"
+	}
+	if err := fsutil.WriteFileAtomic(path, []byte(data), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: failed to hand off to the shell: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readPrompt returns the task given with --prompt or --prompt-file, reading
+// stdin for "-". It returns "" if neither was given.
+func readPrompt(prompt, file string) (string, error) {
+	if prompt != "" && file != "" {
+		return "", fmt.Errorf("use either --prompt or --prompt-file, not both")
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case prompt == "-" || file == "-":
+		data, err = io.ReadAll(os.Stdin)
+	case file != "":
+		data, err = os.ReadFile(file)
+	default:
+		return prompt, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt: %w", err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// promptCommand builds the command that starts the agent on a task from
+// template, substituting {cmd} and the shell-quoted {prompt}. A template
+// that already quotes the placeholder, as in claude "{prompt}", works too.
+func promptCommand(template, cmd, prompt string) string {
+	// One pass, so placeholders inside the prompt or command stay as they are
+	quoted := workspace.ShellQuote(prompt)
+	return strings.NewReplacer(
+		`"{prompt}"`, quoted,
+		`'{prompt}'`, quoted,
+		"{prompt}", quoted,
+		"{cmd}", cmd,
+	).Replace(template)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import "testing"
+
+func TestPromptCommand(t *testing.T) {
+	tests := []struct {
+		template, cmd, prompt string
+		want                  string
+	}{
+		{"{cmd} {prompt}", "claude", "fix it", `claude 'fix it'`},
+		{`{cmd} "{prompt}"`, "claude", "fix it", `claude 'fix it'`},
+		{`{cmd} '{prompt}'`, "claude", "fix it", `claude 'fix it'`},
+		{"{cmd} -p {prompt}", "aider --yes", "go", `aider --yes -p 'go'`},
+		{"{cmd} {prompt}", "claude", "it's", `claude 'it'"'"'s'`},
+		{"{cmd} {prompt}", "claude", `a\b`, `claude 'a'"\\"'b'`},
+		// Placeholders in the prompt and command are left alone
+		{"{cmd} {prompt}", "claude", "explain {cmd}", `claude 'explain {cmd}'`},
+		{"{cmd} {prompt}", "run {prompt}", "x", `run {prompt} 'x'`},
+		{"{prompt}", "claude", "", `''`},
+	}
+	for _, tt := range tests {
+		if got := promptCommand(tt.template, tt.cmd, tt.prompt); got != tt.want {
+			t.Errorf("promptCommand(%q, %q, %q) = %s, want %s", tt.template, tt.cmd, tt.prompt, got, tt.want)
+		}
+	}
+}
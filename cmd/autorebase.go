@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+
+	"github.com/WillCMcC/ws/internal/workspace"
 )
 
 // AutoRebaseCmd handles the 'ws auto-rebase' command.
@@ -42,6 +44,16 @@ func AutoRebaseCmd(args []string) int {
 	fmt.Println("Starting agent to help resolve conflicts...")
 	fmt.Println()
 
+	// The shell integration starts the agent once we return
+	if mgr, err := workspace.NewManager(); err == nil {
+		if ws, err := mgr.Current(); err == nil {
+			if err := mgr.RunHook(workspace.EventPreAgent, ws.Name, ws.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+				return 1
+			}
+		}
+	}
+
 	// Exit 0 to signal shell function to start agent
 	return 0
 }
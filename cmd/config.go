@@ -5,37 +5,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
+	"os/exec"
 	"strings"
+	"text/tabwriter"
 
+	"github.com/WillCMcC/ws/internal/config"
+	"github.com/WillCMcC/ws/internal/git"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-type configItem struct {
-	key         string
-	description string
-	example     string
-}
-
-var configItems = []configItem{
-	{
-		key:         "agent_cmd",
-		description: "Command to run when using 'ws ez'. This starts your AI coding agent in the new workspace.",
-		example:     "claude --dangerously-skip-permissions",
-	},
-	{
-		key:         "default_base",
-		description: "Default branch to use as base when creating new workspaces. Leave empty to auto-detect (main/master).",
-		example:     "develop",
-	},
-	{
-		key:         "directory",
-		description: "Pattern for workspace directory location. Use {repo} as placeholder for repository name.",
-		example:     "../{repo}-ws",
-	},
-}
-
 // Styles
 var (
 	titleStyle = lipgloss.NewStyle().
@@ -77,27 +56,29 @@ var (
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241")).
 			MarginTop(1)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196"))
 )
 
 type model struct {
-	items    []configItem
+	items    []config.Key
 	cursor   int
+	file     *config.File
 	config   map[string]string
 	editing  bool
 	input    string
 	quitting bool
 	saved    bool
 	message  string
+	failed   bool
 }
 
-func initialModel() model {
-	config, _ := loadConfigFile()
-	if config == nil {
-		config = make(map[string]string)
-	}
+func initialModel(file *config.File) model {
 	return model{
-		items:  configItems,
-		config: config,
+		items:  config.Keys,
+		file:   file,
+		config: file.Values(),
 	}
 }
 
@@ -132,7 +113,7 @@ func (m model) handleNavigationInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		m.editing = true
 		// Pre-fill with current value
-		m.input = m.config[m.items[m.cursor].key]
+		m.input = m.config[m.items[m.cursor].Name]
 		m.message = ""
 	}
 	return m, nil
@@ -144,12 +125,29 @@ func (m model) handleEditingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editing = false
 		m.input = ""
 		m.message = ""
+		m.failed = false
 	case "enter":
-		// Save the value
-		key := m.items[m.cursor].key
-		m.config[key] = m.input
-		if err := saveConfigFile(m.config); err != nil {
+		// Validate and save the value
+		key := m.items[m.cursor].Name
+		if m.input != "" {
+			if err := m.items[m.cursor].Validate(m.input); err != nil {
+				m.message = err.Error()
+				m.failed = true
+				return m, nil
+			}
+		}
+		if m.input == "" {
+			m.file.Unset(key)
+		} else if err := m.file.Set(key, m.input); err != nil {
+			m.message = err.Error()
+			m.failed = true
+			return m, nil
+		}
+		m.config = m.file.Values()
+		m.failed = false
+		if err := m.file.Save(); err != nil {
 			m.message = fmt.Sprintf("Error: %v", err)
+			m.failed = true
 		} else {
 			if m.input == "" {
 				m.message = fmt.Sprintf("Cleared %s", key)
@@ -195,12 +193,12 @@ func (m model) View() string {
 			style = selectedStyle
 		}
 
-		value := m.config[item.key]
+		value := m.config[item.Name]
 		if value == "" {
 			value = "(not set)"
 		}
 
-		line := fmt.Sprintf("%s%s", cursor, style.Render(item.key))
+		line := fmt.Sprintf("%s%s", cursor, style.Render(item.Name))
 		b.WriteString(line)
 		b.WriteString("  ")
 		b.WriteString(valueStyle.Render(value))
@@ -209,7 +207,8 @@ func (m model) View() string {
 
 	// Tooltip for selected item
 	selected := m.items[m.cursor]
-	tooltip := selected.description + "\n" + exampleStyle.Render("Example: "+selected.example)
+	tooltip := selected.Description + "\n" +
+		exampleStyle.Render(fmt.Sprintf("Type: %s • Default: %s • Example: %s", selected.Type, displayDefault(selected), selected.Example))
 	b.WriteString(tooltipStyle.Render(tooltip))
 	b.WriteString("\n")
 
@@ -220,7 +219,17 @@ func (m model) View() string {
 		b.WriteString(inputStyle.Render(m.input))
 		b.WriteString("█")
 		b.WriteString("\n")
+		if m.failed && m.message != "" {
+			b.WriteString("\n")
+			b.WriteString(errorStyle.Render("✗ " + m.message))
+		}
+		b.WriteString("\n")
 		b.WriteString(helpStyle.Render("enter: save • esc: cancel • ctrl+u: clear"))
+	} else if m.failed && m.message != "" {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("✗ " + m.message))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("↑/↓: navigate • enter: edit • q: quit"))
 	} else if m.message != "" {
 		b.WriteString("\n")
 		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render("✓ " + m.message))
@@ -243,18 +252,26 @@ func ConfigCmd(args []string) int {
 		fmt.Fprintf(os.Stderr, "Manage ws configuration.\n\n")
 		fmt.Fprintf(os.Stderr, "Without arguments, opens interactive config editor.\n\n")
 		fmt.Fprintf(os.Stderr, "Subcommands:\n")
-		fmt.Fprintf(os.Stderr, "  set <key> <value>   Set a configuration value\n")
-		fmt.Fprintf(os.Stderr, "  get <key>           Get a configuration value\n")
-		fmt.Fprintf(os.Stderr, "  list                List all configuration values\n")
-		fmt.Fprintf(os.Stderr, "  path                Show config file path\n\n")
+		fmt.Fprintf(os.Stderr, "  set [--repo] <key> <value>   Set a configuration value\n")
+		fmt.Fprintf(os.Stderr, "  get [--repo] <key>           Get a configuration value\n")
+		fmt.Fprintf(os.Stderr, "  unset [--repo] <key>         Remove a configuration value\n")
+		fmt.Fprintf(os.Stderr, "  edit [--repo]                Open the config file in $EDITOR\n")
+		fmt.Fprintf(os.Stderr, "  list [--show-origin]         List all configuration values\n")
+		fmt.Fprintf(os.Stderr, "  path [--repo]                Show config file path\n\n")
+		fmt.Fprintf(os.Stderr, "The global config lives in ~/.config/ws/config. A repository can commit\n")
+		fmt.Fprintf(os.Stderr, "its own %s, which is layered on top of it; --repo reads or\n", config.RepoConfigFile)
+		fmt.Fprintf(os.Stderr, "writes that file instead. WS_* environment variables override both.\n\n")
 		fmt.Fprintf(os.Stderr, "Available keys:\n")
-		for _, item := range configItems {
-			fmt.Fprintf(os.Stderr, "  %-14s %s\n", item.key, item.description)
+		for _, key := range config.Keys {
+			fmt.Fprintf(os.Stderr, "  %-17s %s\n", key.Name, key.Description)
 		}
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  ws config                                              # Interactive mode\n")
 		fmt.Fprintf(os.Stderr, "  ws config set agent_cmd \"claude --dangerously-skip-permissions\"\n")
+		fmt.Fprintf(os.Stderr, "  ws config set --repo default_base develop\n")
+		fmt.Fprintf(os.Stderr, "  ws config set agent_processes \"claude, aider\"\n")
 		fmt.Fprintf(os.Stderr, "  ws config get agent_cmd\n")
+		fmt.Fprintf(os.Stderr, "  ws config list --show-origin\n")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -274,11 +291,14 @@ func ConfigCmd(args []string) int {
 		return configSet(subArgs)
 	case "get":
 		return configGet(subArgs)
+	case "unset":
+		return configUnset(subArgs)
+	case "edit":
+		return configEdit(subArgs)
 	case "list":
-		return configList()
+		return configList(subArgs)
 	case "path":
-		fmt.Println(getConfigPath())
-		return 0
+		return configPath(subArgs)
 	default:
 		fmt.Fprintf(os.Stderr, "ws config: unknown subcommand '%s'\n", subCmd)
 		fs.Usage()
@@ -287,7 +307,12 @@ func ConfigCmd(args []string) int {
 }
 
 func runInteractiveConfig() int {
-	p := tea.NewProgram(initialModel())
+	file, err := config.OpenFile(getConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws config: failed to read config: %v\n", err)
+		return 1
+	}
+	p := tea.NewProgram(initialModel(file))
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
@@ -296,28 +321,47 @@ func runInteractiveConfig() int {
 }
 
 func configSet(args []string) int {
+	fs := flag.NewFlagSet("config set", flag.ExitOnError)
+	repo := fs.Bool("repo", false, "Write to the repository config file")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	args = fs.Args()
+
 	if len(args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: ws config set <key> <value>\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws config set [--repo] <key> <value>\n")
 		return 1
 	}
 
 	key := args[0]
 	value := strings.Join(args[1:], " ")
 
-	if !isValidKey(key) {
-		fmt.Fprintf(os.Stderr, "ws config: unknown key '%s'\n", key)
-		fmt.Fprintf(os.Stderr, "Available keys: %s\n", strings.Join(getConfigKeyNames(), ", "))
+	schemaKey, ok := lookupKey(key)
+	if !ok {
+		return 1
+	}
+	if err := schemaKey.Validate(value); err != nil {
+		fmt.Fprintf(os.Stderr, "ws config: invalid value: %v\n", err)
 		return 1
 	}
 
-	config, err := loadConfigFile()
+	path, ok := configFilePath(*repo)
+	if !ok {
+		return 2
+	}
+
+	file, err := config.OpenFile(path)
 	if err != nil {
-		config = make(map[string]string)
+		fmt.Fprintf(os.Stderr, "ws config: failed to read config: %v\n", err)
+		return 1
 	}
 
-	config[key] = value
+	if err := file.Set(key, value); err != nil {
+		fmt.Fprintf(os.Stderr, "ws config: invalid value: %v\n", err)
+		return 1
+	}
 
-	if err := saveConfigFile(config); err != nil {
+	if err := file.Save(); err != nil {
 		fmt.Fprintf(os.Stderr, "ws config: failed to save config: %v\n", err)
 		return 1
 	}
@@ -326,27 +370,165 @@ func configSet(args []string) int {
 	return 0
 }
 
-func configGet(args []string) int {
+func configUnset(args []string) int {
+	fs := flag.NewFlagSet("config unset", flag.ExitOnError)
+	repo := fs.Bool("repo", false, "Remove from the repository config file")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	args = fs.Args()
+
 	if len(args) < 1 {
-		fmt.Fprintf(os.Stderr, "Usage: ws config get <key>\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws config unset [--repo] <key>\n")
 		return 1
 	}
 
 	key := args[0]
-
-	if !isValidKey(key) {
-		fmt.Fprintf(os.Stderr, "ws config: unknown key '%s'\n", key)
-		fmt.Fprintf(os.Stderr, "Available keys: %s\n", strings.Join(getConfigKeyNames(), ", "))
+	if _, ok := lookupKey(key); !ok {
 		return 1
 	}
 
-	config, err := loadConfigFile()
+	path, ok := configFilePath(*repo)
+	if !ok {
+		return 2
+	}
+
+	file, err := config.OpenFile(path)
 	if err != nil {
-		fmt.Println("(not set)")
+		fmt.Fprintf(os.Stderr, "ws config: failed to read config: %v\n", err)
+		return 1
+	}
+
+	if !file.Unset(key) {
+		fmt.Printf("%s is not set\n", key)
 		return 0
 	}
 
-	if value, ok := config[key]; ok {
+	if err := file.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "ws config: failed to save config: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Unset %s\n", key)
+	return 0
+}
+
+// configEdit opens a copy of the config file in the user's editor and
+// only replaces the real file once the edited copy validates.
+func configEdit(args []string) int {
+	fs := flag.NewFlagSet("config edit", flag.ExitOnError)
+	repo := fs.Bool("repo", false, "Edit the repository config file")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	path, ok := configFilePath(*repo)
+	if !ok {
+		return 2
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "ws config: failed to read config: %v\n", err)
+		return 1
+	}
+
+	tmp, err := os.CreateTemp("", "ws-config-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws config: %v\n", err)
+		return 1
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	_, err = tmp.Write(original)
+	tmp.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws config: %v\n", err)
+		return 1
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if err := runEditor(tmpPath); err != nil {
+			fmt.Fprintf(os.Stderr, "ws config: editor failed: %v\n", err)
+			return 1
+		}
+
+		edited, err := config.OpenFile(tmpPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ws config: %v\n", err)
+			return 1
+		}
+
+		errs := edited.Validate()
+		if len(errs) == 0 {
+			edited.Path = path
+			if err := edited.Save(); err != nil {
+				fmt.Fprintf(os.Stderr, "ws config: failed to save config: %v\n", err)
+				return 1
+			}
+			fmt.Printf("Saved %s\n", shortenPath(path))
+			return 0
+		}
+
+		fmt.Fprintf(os.Stderr, "ws config: %s has errors:\n", shortenPath(path))
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "    %v\n", err)
+		}
+		fmt.Fprint(os.Stderr, "Edit again? [Y/n] ")
+		response, err := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if err != nil || response == "n" || response == "no" {
+			fmt.Fprintf(os.Stderr, "Changes discarded.\n")
+			return 1
+		}
+	}
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, falling back to vi.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Go through the shell so editors with arguments ("code -w") work
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func configGet(args []string) int {
+	fs := flag.NewFlagSet("config get", flag.ExitOnError)
+	repo := fs.Bool("repo", false, "Read from the repository config file")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	args = fs.Args()
+
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: ws config get [--repo] <key>\n")
+		return 1
+	}
+
+	key := args[0]
+
+	if _, ok := lookupKey(key); !ok {
+		return 1
+	}
+
+	path, ok := configFilePath(*repo)
+	if !ok {
+		return 2
+	}
+
+	values := config.ReadFile(path)
+	if value, ok := values[key]; ok {
 		fmt.Println(value)
 	} else {
 		fmt.Println("(not set)")
@@ -354,19 +536,30 @@ func configGet(args []string) int {
 	return 0
 }
 
-func configList() int {
-	config, _ := loadConfigFile()
+func configList(args []string) int {
+	fs := flag.NewFlagSet("config list", flag.ExitOnError)
+	showOrigin := fs.Bool("show-origin", false, "Show effective values and where each one came from")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if *showOrigin {
+		return configListOrigins()
+	}
+
+	values := config.ReadFile(getConfigPath())
 
 	fmt.Println("ws configuration:")
 	fmt.Println()
 
-	for _, item := range configItems {
+	for _, key := range config.Keys {
 		value := "(not set)"
-		if v, ok := config[item.key]; ok && v != "" {
+		if v, ok := values[key.Name]; ok && v != "" {
 			value = v
 		}
-		fmt.Printf("  %s\n", item.key)
-		fmt.Printf("    %s\n", item.description)
+		fmt.Printf("  %s (%s)\n", key.Name, key.Type)
+		fmt.Printf("    %s\n", key.Description)
+		fmt.Printf("    Default: %s\n", displayDefault(key))
 		fmt.Printf("    Value: %s\n\n", value)
 	}
 
@@ -374,96 +567,81 @@ func configList() int {
 	return 0
 }
 
-func isValidKey(key string) bool {
-	for _, item := range configItems {
-		if item.key == key {
-			return true
+// configListOrigins prints the effective configuration for the current
+// repository along with the layer each value was taken from.
+func configListOrigins() int {
+	// Outside a repo there is simply no repo layer
+	repoRoot, _ := git.FindRepoRoot()
+	cfg := config.Load(repoRoot)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tORIGIN")
+	for _, key := range config.Keys {
+		value := key.Get(cfg)
+		if value == "" {
+			value = "(not set)"
 		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key.Name, value, shortenPath(cfg.Origin(key.Name).String()))
 	}
-	return false
-}
-
-func getConfigPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
-	return filepath.Join(home, ".config", "ws", "config")
+	w.Flush()
+	return 0
 }
 
-func loadConfigFile() (map[string]string, error) {
-	path := getConfigPath()
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
+func configPath(args []string) int {
+	fs := flag.NewFlagSet("config path", flag.ExitOnError)
+	repo := fs.Bool("repo", false, "Show the repository config file path")
+	if err := fs.Parse(args); err != nil {
+		return 1
 	}
-	defer file.Close()
 
-	config := make(map[string]string)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			config[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-		}
+	path, ok := configFilePath(*repo)
+	if !ok {
+		return 2
 	}
-	return config, scanner.Err()
+	fmt.Println(path)
+	return 0
 }
 
-func saveConfigFile(config map[string]string) error {
-	path := getConfigPath()
-
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+// configFilePath returns the global config path, or the repository config
+// path when repo is set. It reports false if the repo root can't be found.
+func configFilePath(repo bool) (string, bool) {
+	if !repo {
+		return getConfigPath(), true
 	}
-
-	file, err := os.Create(path)
+	repoRoot, err := git.FindRepoRoot()
 	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	for key, value := range config {
-		if _, err := fmt.Fprintf(file, "%s=%s\n", key, value); err != nil {
-			return err
-		}
+		fmt.Fprintf(os.Stderr, "ws config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "    --repo must be used from within a git repository.\n")
+		return "", false
 	}
-	return nil
+	return config.RepoPath(repoRoot), true
 }
 
-func getConfigKeyNames() []string {
-	keys := make([]string, 0, len(configItems))
-	for _, item := range configItems {
-		keys = append(keys, item.key)
+// lookupKey finds a schema key, printing the available keys if it is unknown.
+func lookupKey(name string) (config.Key, bool) {
+	key, ok := config.Lookup(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "ws config: unknown key '%s'\n", name)
+		fmt.Fprintf(os.Stderr, "Available keys: %s\n", strings.Join(config.KeyNames(), ", "))
 	}
-	return keys
+	return key, ok
 }
 
-// GetConfigValue returns a config value, checking file config then env vars.
-func GetConfigValue(key string) string {
-	// First check config file
-	config, err := loadConfigFile()
-	if err == nil {
-		if value, ok := config[key]; ok {
-			return value
-		}
+// displayDefault formats a key's default value for display.
+func displayDefault(key config.Key) string {
+	if def := key.Default(); def != "" {
+		return def
 	}
+	return "(none)"
+}
 
-	// Fall back to environment variables
-	switch key {
-	case "agent_cmd":
-		return os.Getenv("WS_AGENT_CMD")
-	case "default_base":
-		return os.Getenv("WS_DEFAULT_BASE")
-	case "directory":
-		return os.Getenv("WS_DIRECTORY")
-	}
+func getConfigPath() string {
+	return config.GlobalPath()
+}
 
-	return ""
+// GetConfigValue returns the effective value of a config key for the
+// current repository.
+func GetConfigValue(key string) string {
+	repoRoot, _ := git.FindRepoRoot()
+	return config.Load(repoRoot).Value(key)
 }
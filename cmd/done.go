@@ -1,22 +1,30 @@
 package cmd
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/WillCMcC/ws/internal/git"
 	"github.com/WillCMcC/ws/internal/workspace"
+	"github.com/mattn/go-isatty"
 )
 
 // DoneCmd handles the 'ws done' command.
 func DoneCmd(args []string) int {
 	fs := flag.NewFlagSet("done", flag.ExitOnError)
-	force := fs.Bool("force", false, "Remove even with uncommitted changes")
+	force := fs.Bool("force", false, "Remove even with uncommitted changes or running processes")
 	keepBranch := fs.Bool("keep-branch", false, "Don't delete the branch")
+	stopAgents := fs.Bool("stop-agents", false, "Stop agents and other processes running in the workspace first")
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ws done <name> [--force] [--keep-branch]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws done [name] [--force] [--keep-branch] [--stop-agents]\n\n")
 		fmt.Fprintf(os.Stderr, "Remove a workspace (worktree + optionally branch).\n\n")
+		fmt.Fprintf(os.Stderr, "If no name is given on a terminal, pick one from a list.\n\n")
+		fmt.Fprintf(os.Stderr, "Refuses while processes are running in the workspace; --stop-agents\n")
+		fmt.Fprintf(os.Stderr, "and --force stop them (SIGTERM, then SIGKILL) before removing it.\n\n")
 		fmt.Fprintf(os.Stderr, "Arguments:\n")
 		fmt.Fprintf(os.Stderr, "  name    Workspace to remove\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
@@ -27,14 +35,12 @@ func DoneCmd(args []string) int {
 		return 1
 	}
 
-	if fs.NArg() < 1 {
+	if fs.NArg() < 1 && !interactive() {
 		fmt.Fprintf(os.Stderr, "ws: missing workspace name\n")
 		fmt.Fprintf(os.Stderr, "    Usage: ws done <name>\n")
 		return 1
 	}
 
-	name := fs.Arg(0)
-
 	mgr, err := workspace.NewManager()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
@@ -45,10 +51,31 @@ func DoneCmd(args []string) int {
 		return 1
 	}
 
-	if err := mgr.Remove(name, *force, *keepBranch); err != nil {
-		// Don't print error again if it's about uncommitted changes
-		// (already printed by Remove)
-		if err.Error() != "workspace has uncommitted changes" {
+	name := fs.Arg(0)
+	if name == "" {
+		var code int
+		if name, code = pickTarget(mgr, "remove"); code != 0 {
+			return code
+		}
+	}
+	ws, code := confirmWorkspace(mgr, name, "remove")
+	if code != 0 {
+		return code
+	}
+
+	opts := workspace.RemoveOptions{
+		Force:         *force,
+		KeepBranch:    *keepBranch,
+		StopProcesses: *stopAgents,
+	}
+	if !opts.Force && !opts.StopProcesses {
+		opts.StopProcesses = offerToStopProcesses(ws)
+	}
+
+	if err := mgr.Remove(ws.Name, opts); err != nil {
+		// Don't print error again if it's about uncommitted changes or
+		// running processes (already printed by Remove)
+		if err.Error() != "workspace has uncommitted changes" && err.Error() != "workspace has running processes" {
 			fmt.Fprintf(os.Stderr, "ws: %v\n", err)
 		}
 		return 1
@@ -56,3 +83,37 @@ func DoneCmd(args []string) int {
 
 	return 0
 }
+
+// offerToStopProcesses lists the processes still running in a workspace
+// and, on a terminal, asks whether to stop them so it can be removed. It
+// stays quiet when the removal is going to be refused anyway.
+func offerToStopProcesses(ws *workspace.Workspace) bool {
+	if !interactive() {
+		return false
+	}
+	if dirty, _, err := git.HasUncommittedChanges(ws.Path); err == nil && dirty {
+		return false
+	}
+
+	procs, err := workspace.RunningProcesses(ws.Path)
+	if err != nil || len(procs) == 0 {
+		return false
+	}
+
+	// These can be the user's own shells and editors, so only stop them
+	// when asked to
+	fmt.Fprintf(os.Stderr, "%d process(es) still running in %s:\n", len(procs), ws.Name)
+	for _, p := range procs {
+		fmt.Fprintf(os.Stderr, "  %d  %s\n", p.PID, commandLine(p, 60))
+	}
+	fmt.Fprintf(os.Stderr, "Stop all of them (SIGTERM, then SIGKILL) and remove the workspace? [y/N] ")
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// interactive reports whether a user is at the terminal to answer prompts.
+func interactive() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stderr.Fd())
+}
@@ -10,18 +10,26 @@ import (
 )
 
 // EzCmd handles the 'ws ez' command.
-// It creates a workspace and outputs the path + agent command for shell integration.
+// It creates a workspace and starts the agent in it, natively or through
+// the shell integration.
 func EzCmd(args []string) int {
 	fs := flag.NewFlagSet("ez", flag.ExitOnError)
 	from := fs.String("from", "", "Base ref to branch from")
-	noHooks := fs.Bool("no-hooks", false, "Skip post-create hooks")
+	noHooks := fs.Bool("no-hooks", false, "Skip create hooks")
+	launch := fs.String("launch", "", "How to start the agent: auto, shell or native (default from agent_launch)")
+	prompt := fs.String("prompt", "", "Task for the agent ('-' reads it from stdin)")
+	promptFile := fs.String("prompt-file", "", "Read the task for the agent from a file ('-' for stdin)")
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ws ez <name> [--from <ref>] [--no-hooks]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws ez <name> [--from <ref>] [--no-hooks] [--launch <mode>] [--prompt <text> | --prompt-file <file>]\n\n")
 		fmt.Fprintf(os.Stderr, "Create a workspace, navigate to it, and start your agent.\n\n")
 		fmt.Fprintf(os.Stderr, "Configure the agent command with:\n")
 		fmt.Fprintf(os.Stderr, "  ws config set agent_cmd \"claude --dangerously-skip-permissions\"\n\n")
 		fmt.Fprintf(os.Stderr, "Default: claude\n\n")
+		fmt.Fprintf(os.Stderr, "Without the shell integration, or with --launch native, ws runs the\n")
+		fmt.Fprintf(os.Stderr, "agent itself and returns when it exits.\n\n")
+		fmt.Fprintf(os.Stderr, "A prompt is passed to the agent using agent_prompt_template\n")
+		fmt.Fprintf(os.Stderr, "(default: {cmd} {prompt}) and saved with the workspace.\n\n")
 		fmt.Fprintf(os.Stderr, "Arguments:\n")
 		fmt.Fprintf(os.Stderr, "  name    Workspace name (becomes branch and directory name)\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
@@ -38,7 +46,22 @@ func EzCmd(args []string) int {
 		return 1
 	}
 
+	// Accept flags after the name too, as in 'ws ez <name> --prompt ...'
 	name := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "ws: unexpected argument '%s'\n", fs.Arg(0))
+		return 1
+	}
+
+	// Read the prompt first so a bad file doesn't leave a workspace behind
+	task, err := readPrompt(*prompt, *promptFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
 
 	mgr, err := workspace.NewManager()
 	if err != nil {
@@ -50,16 +73,42 @@ func EzCmd(args []string) int {
 		return 1
 	}
 
+	// Check the launch mode and the agent command too before creating
+	// anything
+	mode, code := launchMode(mgr, *launch)
+	if code != 0 {
+		return code
+	}
+	if err := mgr.CheckAgentTrust(); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
 	if err := mgr.Create(name, *from, *noHooks); err != nil {
 		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
 		return 1
 	}
 
-	return 0
+	ws, err := mgr.Get(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
+	return startAgent(mgr, ws, mode, task)
 }
 
-// GetAgentCmd returns the configured agent command.
+// GetAgentCmd returns the configured agent command, or "" if the
+// repository sets one that isn't trusted.
 func GetAgentCmd() string {
-	cfg := config.Load()
-	return cfg.Agent.Cmd
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		// Outside a repo this falls back to global config and env only
+		return config.Load("").Agent.Cmd
+	}
+	if err := mgr.CheckAgentTrust(); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return ""
+	}
+	return mgr.Config.Agent.Cmd
 }
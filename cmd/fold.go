@@ -5,8 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strings"
+	"syscall"
 
 	"github.com/WillCMcC/ws/internal/git"
 	"github.com/WillCMcC/ws/internal/workspace"
@@ -16,15 +15,21 @@ import (
 func FoldCmd(args []string) int {
 	fs := flag.NewFlagSet("fold", flag.ExitOnError)
 	noDone := fs.Bool("no-done", false, "Don't remove workspace after folding")
+	force := fs.Bool("force", false, "Fold even with processes running in the workspace, then keep it")
+	stopAgents := fs.Bool("stop-agents", false, "Stop agents and other processes running in the workspace first")
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ws fold [name] [--no-done]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws fold [name] [--no-done] [--force] [--stop-agents]\n\n")
 		fmt.Fprintf(os.Stderr, "Rebase workspace onto default branch and merge it in.\n\n")
-		fmt.Fprintf(os.Stderr, "If no name is given, uses the current workspace.\n\n")
+		fmt.Fprintf(os.Stderr, "If no name is given, uses the current workspace, or outside one on a\n")
+		fmt.Fprintf(os.Stderr, "terminal, one picked from a list.\n\n")
+		fmt.Fprintf(os.Stderr, "Refuses while an agent or other process is running in the workspace,\n")
+		fmt.Fprintf(os.Stderr, "since rebasing would change files under it.\n\n")
 		fmt.Fprintf(os.Stderr, "Steps performed:\n")
 		fmt.Fprintf(os.Stderr, "  1. Rebase workspace branch onto default branch\n")
 		fmt.Fprintf(os.Stderr, "  2. Fast-forward merge into default branch\n")
-		fmt.Fprintf(os.Stderr, "  3. Remove workspace (unless --no-done)\n\n")
+		fmt.Fprintf(os.Stderr, "  3. Remove workspace (unless --no-done, or --force left processes\n")
+		fmt.Fprintf(os.Stderr, "     running in it)\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		fs.PrintDefaults()
 	}
@@ -49,40 +54,29 @@ func FoldCmd(args []string) int {
 
 	if fs.NArg() >= 1 {
 		// Workspace name provided
-		wsName = fs.Arg(0)
-		ws, err := mgr.Get(wsName)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "ws: %v\n", err)
-			return 1
+		ws, code := confirmWorkspace(mgr, fs.Arg(0), "fold")
+		if code != 0 {
+			return code
 		}
+		wsName = ws.Name
 		wsPath = ws.Path
 	} else {
-		// Try to detect current workspace from cwd
-		cwd, err := os.Getwd()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "ws: failed to get current directory: %v\n", err)
-			return 1
-		}
-
-		workspaces, err := mgr.List()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "ws: failed to list workspaces: %v\n", err)
-			return 1
-		}
-
-		for _, ws := range workspaces {
-			if isInOrEqualDir(cwd, ws.Path) {
-				wsName = ws.Name
-				wsPath = ws.Path
-				break
+		// Try to detect current workspace from cwd, then ask
+		ws, err := mgr.Current()
+		if err != nil && interactive() {
+			name, code := pickTarget(mgr, "fold")
+			if code != 0 {
+				return code
 			}
+			ws, err = mgr.Get(name)
 		}
-
-		if wsName == "" {
-			fmt.Fprintf(os.Stderr, "ws: not in a workspace\n")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ws: %v\n", err)
 			fmt.Fprintf(os.Stderr, "    Run from within a workspace, or specify: ws fold <name>\n")
 			return 1
 		}
+		wsName = ws.Name
+		wsPath = ws.Path
 	}
 
 	defaultBranch := mgr.Config.GetDefaultBase()
@@ -99,6 +93,31 @@ func FoldCmd(args []string) int {
 		return 1
 	}
 
+	// Check for running agents and other processes
+	procs, err := workspace.RunningProcesses(wsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if len(procs) > 0 && *stopAgents {
+		if err := workspace.StopProcesses(procs, syscall.SIGTERM, workspace.StopGrace); err != nil {
+			fmt.Fprintf(os.Stderr, "ws: failed to stop processes: %v\n", err)
+			return 1
+		}
+	} else if len(procs) > 0 && !*force {
+		fmt.Fprintf(os.Stderr, "ws: workspace '%s' has %d process(es) running:\n", wsName, len(procs))
+		for _, p := range procs {
+			fmt.Fprintf(os.Stderr, "      %d  %s\n", p.PID, commandLine(p, 60))
+		}
+		fmt.Fprintf(os.Stderr, "    ws fold --stop-agents  # stop them, then fold\n")
+		fmt.Fprintf(os.Stderr, "    ws fold --force        # fold anyway\n")
+		return 1
+	}
+
+	if err := mgr.RunHook(workspace.EventPreFold, wsName, wsPath); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
 	fmt.Printf("Folding workspace '%s' into '%s'...\n\n", wsName, defaultBranch)
 
 	// Step 1: Fetch latest default branch in main repo
@@ -111,6 +130,8 @@ func FoldCmd(args []string) int {
 	// Step 2: Rebase workspace onto default branch
 	fmt.Printf("Rebasing '%s' onto '%s'...\n", wsName, defaultBranch)
 	if err := runGitCmd(wsPath, "rebase", defaultBranch); err != nil {
+		// The fold fails either way, so an aborting policy changes nothing
+		_ = mgr.RunHook(workspace.EventOnRebaseConflict, wsName, wsPath)
 		fmt.Fprintf(os.Stderr, "\nws: rebase failed - resolve conflicts then try again\n")
 		fmt.Fprintf(os.Stderr, "    ws auto-rebase     # get agent help with conflicts\n")
 		fmt.Fprintf(os.Stderr, "    git rebase --abort # or abort\n")
@@ -135,11 +156,21 @@ func FoldCmd(args []string) int {
 
 	fmt.Printf("\nSuccessfully merged '%s' into '%s'\n", wsName, defaultBranch)
 
+	if err := mgr.RunHook(workspace.EventPostFold, wsName, wsPath); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		fmt.Fprintf(os.Stderr, "    Workspace kept; remove it with: ws done %s\n", wsName)
+		return 1
+	}
+
 	// Step 4: Clean up workspace (unless --no-done)
-	if !*noDone {
+	if !*noDone && len(procs) > 0 && !*stopAgents {
+		// --force folds around the processes, it doesn't stop them
+		fmt.Printf("Workspace kept since processes are running in it; remove it with: ws done %s\n", wsName)
+	} else if !*noDone {
 		fmt.Printf("Cleaning up workspace...\n")
-		// Use force since we just merged everything
-		if err := mgr.Remove(wsName, true, false); err != nil {
+		// Not forced: the branch is merged, but anything written to the
+		// workspace since the check above is not
+		if err := mgr.Remove(wsName, workspace.RemoveOptions{StopProcesses: *stopAgents}); err != nil {
 			fmt.Fprintf(os.Stderr, "ws: warning: failed to remove workspace: %v\n", err)
 		}
 	}
@@ -158,23 +189,3 @@ func runGitCmd(dir string, args ...string) error {
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
-
-// isInOrEqualDir checks if path is inside or equal to dir.
-func isInOrEqualDir(path, dir string) bool {
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return false
-	}
-	absDir, err := filepath.Abs(dir)
-	if err != nil {
-		return false
-	}
-	if absPath == absDir {
-		return true
-	}
-	rel, err := filepath.Rel(absDir, absPath)
-	if err != nil {
-		return false
-	}
-	return !strings.HasPrefix(rel, "..")
-}
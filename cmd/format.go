@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/workspace"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+)
+
+// colorNames maps the color names accepted by the color template function
+// to ANSI color numbers. Numbers from 0 to 255 are accepted too.
+var colorNames = map[string]int{
+	"black":   0,
+	"red":     1,
+	"green":   2,
+	"yellow":  3,
+	"blue":    4,
+	"magenta": 5,
+	"cyan":    6,
+	"white":   7,
+	"gray":    8,
+	"grey":    8,
+}
+
+// useColor reports whether the color template function should color its
+// output: only when stdout is a terminal and NO_COLOR isn't set.
+var useColor = isatty.IsTerminal(os.Stdout.Fd()) && os.Getenv("NO_COLOR") == ""
+
+// formatFuncs are the helper functions available to --format templates.
+// Those taking text accept any value, so fields such as .State and .Ahead
+// can be piped to them directly.
+var formatFuncs = template.FuncMap{
+	// short abbreviates a commit hash to 7 characters and replaces the
+	// home directory in a path with ~.
+	"short": func(v any) string {
+		s := fmt.Sprint(v)
+		if strings.HasPrefix(s, "/") {
+			return shortenPath(s)
+		}
+		if len(s) > 7 {
+			return s[:7]
+		}
+		return s
+	},
+	// ago describes how long ago a time was, e.g. "3 hours ago".
+	"ago": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return formatAgo(t)
+	},
+	// truncate shortens s to n columns, ending it with "..." if cut.
+	"truncate": func(n int, v any) string {
+		return truncate(fmt.Sprint(v), n)
+	},
+	// color colors its argument with a named or numbered ANSI color.
+	"color": func(name string, v any) (string, error) {
+		n, ok := colorNames[name]
+		if !ok {
+			var err error
+			if n, err = strconv.Atoi(name); err != nil || n < 0 || n > 255 {
+				return "", fmt.Errorf("unknown color '%s'", name)
+			}
+		}
+		if !useColor {
+			return fmt.Sprint(v), nil
+		}
+		return fmt.Sprintf("\x1b[38;5;%dm%v\x1b[0m", n, v), nil
+	},
+}
+
+// truncate shortens s to fit in max terminal columns, ending it with "..."
+// if cut. It never splits a character, and wide ones count as two columns.
+func truncate(s string, max int) string {
+	if lipgloss.Width(s) <= max {
+		return s
+	}
+	ellipsis := "..."
+	if max <= len(ellipsis) {
+		ellipsis = ""
+	}
+
+	var b strings.Builder
+	width := len(ellipsis)
+	for _, r := range s {
+		w := lipgloss.Width(string(r))
+		if width+w > max {
+			break
+		}
+		b.WriteRune(r)
+		width += w
+	}
+	return b.String() + ellipsis
+}
+
+// parseFormat parses a --format template. The escapes \t and \n are
+// expanded, since shells pass them through literally.
+func parseFormat(format string) (*template.Template, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// outputFormat executes tmpl for each workspace, one per line.
+func outputFormat(tmpl *template.Template, infos []workspace.Info) int {
+	var b strings.Builder
+	for _, info := range infos {
+		b.Reset()
+		if err := tmpl.Execute(&b, info); err != nil {
+			fmt.Fprintf(os.Stderr, "ws: failed to format %s: %v\n", info.Name, err)
+			return 1
+		}
+		fmt.Println(b.String())
+	}
+	return 0
+}
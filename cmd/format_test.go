@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"a longer subject", 10, "a longe..."},
+		{"héllo wörld", 8, "héllo..."},
+		{"日本語のコミット", 9, "日本語..."},
+		{"日本語", 6, "日本語"},
+		{"abcdef", 3, "abc"},
+		{"日本語", 3, "日"},
+		{"", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestFormatUnknownCommit(t *testing.T) {
+	// Fields of a commit that couldn't be read render empty
+	tmpl, err := parseFormat(`{{.Name}}\t{{.LastCommit.SHA | short}}\t{{.LastCommit.Time | ago}}\t{{.LastCommit.Subject | truncate 10}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, workspace.Info{Name: "auth"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got, want := b.String(), "auth\t\t\t"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
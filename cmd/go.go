@@ -11,26 +11,44 @@ import (
 // GoCmd handles the 'ws go' command.
 func GoCmd(args []string) int {
 	fs := flag.NewFlagSet("go", flag.ExitOnError)
+	root := fs.Bool("root", false, "Go to the workspace root rather than the current subdirectory")
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ws go <name>\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws go [name] [--root]\n\n")
 		fmt.Fprintf(os.Stderr, "Navigate to a workspace directory.\n\n")
+		fmt.Fprintf(os.Stderr, "Keeps you in the same subdirectory, or the deepest part of it that\n")
+		fmt.Fprintf(os.Stderr, "exists in the workspace, unless --root is given.\n\n")
+		fmt.Fprintf(os.Stderr, "The name can be abbreviated to any unique prefix or fuzzy match, and\n")
+		fmt.Fprintf(os.Stderr, "'-' goes back to the previous workspace. If no name is given on a\n")
+		fmt.Fprintf(os.Stderr, "terminal, pick one from a list.\n\n")
 		fmt.Fprintf(os.Stderr, "Note: This command prints the path. Use shell integration\n")
-		fmt.Fprintf(os.Stderr, "for the 'cd' to work. Run 'ws init' to set up.\n")
+		fmt.Fprintf(os.Stderr, "for the 'cd' to work. Run 'ws init' to set up.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
 	}
 
 	if err := fs.Parse(args); err != nil {
 		return 1
 	}
 
-	if fs.NArg() < 1 {
+	// Accept flags after the name too, as in 'ws go <name> --root'
+	name := fs.Arg(0)
+	if name != "" {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+		if fs.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "ws: unexpected argument '%s'\n", fs.Arg(0))
+			return 1
+		}
+	}
+
+	if name == "" && !interactive() {
 		fmt.Fprintf(os.Stderr, "ws: missing workspace name\n")
 		fmt.Fprintf(os.Stderr, "    Usage: ws go <name>\n")
 		return 1
 	}
 
-	name := fs.Arg(0)
-
 	mgr, err := workspace.NewManager()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
@@ -41,19 +59,28 @@ func GoCmd(args []string) int {
 		return 1
 	}
 
-	path, err := mgr.GetPath(name)
-	if err != nil {
-		// Show helpful error with available workspaces
-		fmt.Fprintf(os.Stderr, "ws: workspace '%s' not found\n", name)
-		workspaces, listErr := mgr.List()
-		if listErr == nil && len(workspaces) > 0 {
-			fmt.Fprintf(os.Stderr, "    Available workspaces:\n")
-			for _, ws := range workspaces {
-				fmt.Fprintf(os.Stderr, "      %s\n", ws.Name)
-			}
+	if name == "" {
+		var code int
+		if name, code = pickTarget(mgr, "go to"); code != 0 {
+			return code
 		}
-		fmt.Fprintf(os.Stderr, "    To create it: ws new %s\n", name)
-		return 3
+	}
+
+	ws, code := resolveWorkspace(mgr, name)
+	if code != 0 {
+		if code == 3 && name != workspace.PreviousName {
+			fmt.Fprintf(os.Stderr, "    To create it: ws new %s\n", name)
+		}
+		return code
+	}
+
+	if err := mgr.RecordVisit(ws.Name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record visit: %v\n", err)
+	}
+
+	path := ws.Path
+	if !*root {
+		path = mgr.Subdir(path)
 	}
 
 	// Print just the path to stdout (shell integration will use this)
@@ -11,10 +11,15 @@ import (
 // HomeCmd handles the 'ws home' command.
 func HomeCmd(args []string) int {
 	fs := flag.NewFlagSet("home", flag.ExitOnError)
+	root := fs.Bool("root", false, "Go to the repository root rather than the current subdirectory")
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ws home\n\n")
-		fmt.Fprintf(os.Stderr, "Navigate to the main repository directory.\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws home [--root]\n\n")
+		fmt.Fprintf(os.Stderr, "Navigate to the main repository directory.\n\n")
+		fmt.Fprintf(os.Stderr, "Keeps you in the same subdirectory, or the deepest part of it that\n")
+		fmt.Fprintf(os.Stderr, "exists in the main repository, unless --root is given.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -31,7 +36,12 @@ func HomeCmd(args []string) int {
 		return 1
 	}
 
+	path := mgr.RepoRoot
+	if !*root {
+		path = mgr.Subdir(path)
+	}
+
 	// Print just the path to stdout (shell integration will use this)
-	fmt.Println(mgr.RepoRoot)
+	fmt.Println(path)
 	return 0
 }
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+// HookCmd handles the 'ws hook' command.
+// It runs the configured hook for an event. The shell integration uses it
+// to fire post-agent once the agent it started has exited.
+func HookCmd(args []string) int {
+	fs := flag.NewFlagSet("hook", flag.ExitOnError)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ws hook <event> [name]\n\n")
+		fmt.Fprintf(os.Stderr, "Run the hook configured for an event.\n\n")
+		fmt.Fprintf(os.Stderr, "If no name is given, uses the current workspace.\n\n")
+		fmt.Fprintf(os.Stderr, "Events:\n")
+		for _, event := range workspace.Events {
+			fmt.Fprintf(os.Stderr, "  %s\n", event)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "ws: missing hook event\n")
+		fmt.Fprintf(os.Stderr, "    Usage: ws hook <event> [name]\n")
+		return 1
+	}
+
+	event, err := workspace.ParseEvent(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if err.Error() == "not a git repository" {
+			fmt.Fprintf(os.Stderr, "    Run this command from within a git repository.\n")
+			return 2
+		}
+		return 1
+	}
+
+	var ws *workspace.Workspace
+	if fs.NArg() >= 2 {
+		var code int
+		if ws, code = resolveWorkspace(mgr, fs.Arg(1)); code != 0 {
+			return code
+		}
+	} else if ws, err = mgr.Current(); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
+	// The shell integration fires post-agent when the agent it ran exits
+	if event == workspace.EventPostAgent {
+		if err := mgr.RecordAgentExit(ws.Name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save workspace metadata: %v\n", err)
+		}
+	}
+
+	if err := mgr.RunHook(event, ws.Name, ws.Path); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+	return 0
+}
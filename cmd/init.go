@@ -12,24 +12,23 @@ import (
 
 var bashIntegration = `# ws - workspace manager
 ws() {
-    if [[ "$1" == "go" && -n "$2" ]]; then
+    if [[ "$1" == "go" ]]; then
+        # Errors, and the picker when no name is given, go to the terminal
         local target
-        target=$(command ws go "$2" 2>/dev/null)
+        target=$(command ws "$@")
         local exit_code=$?
         if [[ $exit_code -eq 0 && -n "$target" && -d "$target" ]]; then
             cd "$target" || return 1
-        else
-            command ws go "$2"
-            return $exit_code
         fi
+        return $exit_code
     elif [[ "$1" == "home" ]]; then
         local target
-        target=$(command ws home 2>/dev/null)
+        target=$(command ws "$@" 2>/dev/null)
         local exit_code=$?
         if [[ $exit_code -eq 0 && -n "$target" && -d "$target" ]]; then
             cd "$target" || return 1
         else
-            command ws home
+            command ws "$@"
             return $exit_code
         fi
     elif [[ "$1" == "new" && -n "$2" ]]; then
@@ -43,17 +42,20 @@ ws() {
             fi
         fi
         return $exit_code
-    elif [[ "$1" == "ez" && -n "$2" ]]; then
-        command ws "$@"
+    elif [[ "$1" == "ez" || "$1" == "agent" || "$1" == "ui" ]]; then
+        # ws writes the workspace to cd into, then any agent command to run
+        local handoff
+        handoff=$(mktemp "${TMPDIR:-/tmp}/ws.XXXXXX") || return 1
+        WS_HANDOFF="$handoff" command ws "$@"
         local exit_code=$?
-        if [[ $exit_code -eq 0 ]]; then
-            local target
-            target=$(command ws go "$2" 2>/dev/null)
-            if [[ -n "$target" && -d "$target" ]]; then
-                cd "$target" || return 1
-                local agent_cmd
-                agent_cmd=$(command ws agent-cmd)
+        local target agent_cmd
+        { IFS= read -r target; agent_cmd=$(cat); } < "$handoff"
+        rm -f "$handoff"
+        if [[ -n "$target" && -d "$target" ]]; then
+            cd "$target" || return 1
+            if [[ $exit_code -eq 0 && -n "$agent_cmd" ]]; then
                 eval "$agent_cmd"
+                command ws hook post-agent
             fi
         fi
         return $exit_code
@@ -74,6 +76,7 @@ ws() {
             local agent_cmd
             agent_cmd=$(command ws agent-cmd)
             eval "$agent_cmd" "Help me finish this rebase. There are merge conflicts that need to be resolved. Look at the conflicted files, understand both sides, and resolve them appropriately. Ask me any questions if you're unsure about the intent. Once resolved, run 'git add' on the fixed files and 'git rebase --continue'."
+            command ws hook post-agent
         fi
         return $exit_code
     else
@@ -84,10 +87,10 @@ ws() {
 # Optional: completion
 _ws_completions() {
     if [[ ${COMP_CWORD} -eq 1 ]]; then
-        COMPREPLY=($(compgen -W "new ez list go home done fold auto-rebase status prune init config" -- "${COMP_WORDS[1]}"))
+        COMPREPLY=($(compgen -W "new ez agent list go home done fold auto-rebase status ps kill note ui wait prune init config" -- "${COMP_WORDS[1]}"))
     elif [[ ${COMP_CWORD} -eq 2 ]]; then
         case "${COMP_WORDS[1]}" in
-            go|done|fold|status)
+            go|done|fold|status|ps|kill|note|wait|agent)
                 local workspaces
                 workspaces=$(command ws list --quiet 2>/dev/null)
                 COMPREPLY=($(compgen -W "$workspaces" -- "${COMP_WORDS[2]}"))
@@ -99,24 +102,23 @@ complete -F _ws_completions ws`
 
 var zshIntegration = `# ws - workspace manager
 ws() {
-    if [[ "$1" == "go" && -n "$2" ]]; then
+    if [[ "$1" == "go" ]]; then
+        # Errors, and the picker when no name is given, go to the terminal
         local target
-        target=$(command ws go "$2" 2>/dev/null)
+        target=$(command ws "$@")
         local exit_code=$?
         if [[ $exit_code -eq 0 && -n "$target" && -d "$target" ]]; then
             cd "$target"
-        else
-            command ws go "$2"
-            return $exit_code
         fi
+        return $exit_code
     elif [[ "$1" == "home" ]]; then
         local target
-        target=$(command ws home 2>/dev/null)
+        target=$(command ws "$@" 2>/dev/null)
         local exit_code=$?
         if [[ $exit_code -eq 0 && -n "$target" && -d "$target" ]]; then
             cd "$target"
         else
-            command ws home
+            command ws "$@"
             return $exit_code
         fi
     elif [[ "$1" == "new" && -n "$2" ]]; then
@@ -130,17 +132,20 @@ ws() {
             fi
         fi
         return $exit_code
-    elif [[ "$1" == "ez" && -n "$2" ]]; then
-        command ws "$@"
+    elif [[ "$1" == "ez" || "$1" == "agent" || "$1" == "ui" ]]; then
+        # ws writes the workspace to cd into, then any agent command to run
+        local handoff
+        handoff=$(mktemp "${TMPDIR:-/tmp}/ws.XXXXXX") || return 1
+        WS_HANDOFF="$handoff" command ws "$@"
         local exit_code=$?
-        if [[ $exit_code -eq 0 ]]; then
-            local target
-            target=$(command ws go "$2" 2>/dev/null)
-            if [[ -n "$target" && -d "$target" ]]; then
-                cd "$target"
-                local agent_cmd
-                agent_cmd=$(command ws agent-cmd)
+        local target agent_cmd
+        { IFS= read -r target; agent_cmd=$(cat); } < "$handoff"
+        rm -f "$handoff"
+        if [[ -n "$target" && -d "$target" ]]; then
+            cd "$target"
+            if [[ $exit_code -eq 0 && -n "$agent_cmd" ]]; then
                 eval "$agent_cmd"
+                command ws hook post-agent
             fi
         fi
         return $exit_code
@@ -161,6 +166,7 @@ ws() {
             local agent_cmd
             agent_cmd=$(command ws agent-cmd)
             eval "$agent_cmd" "Help me finish this rebase. There are merge conflicts that need to be resolved. Look at the conflicted files, understand both sides, and resolve them appropriately. Ask me any questions if you're unsure about the intent. Once resolved, run 'git add' on the fixed files and 'git rebase --continue'."
+            command ws hook post-agent
         fi
         return $exit_code
     else
@@ -174,6 +180,7 @@ _ws() {
     commands=(
         'new:Create a new workspace'
         'ez:Create workspace and start agent'
+        'agent:Start agent in a workspace'
         'list:List all workspaces'
         'go:Navigate to a workspace'
         'home:Navigate to main repository'
@@ -181,6 +188,11 @@ _ws() {
         'fold:Rebase and merge workspace'
         'auto-rebase:Resolve rebase conflicts with agent'
         'status:Show workspace status'
+        'ps:List processes in workspaces'
+        'kill:Stop processes in a workspace'
+        'note:Attach a note to a workspace'
+        'ui:Open the workspace dashboard'
+        'wait:Wait for a workspace to reach a condition'
         'prune:Clean up stale worktrees'
         'init:Set up shell integration'
         'config:Manage configuration'
@@ -190,7 +202,7 @@ _ws() {
         _describe 'command' commands
     elif (( CURRENT == 3 )); then
         case "$words[2]" in
-            go|done|fold)
+            go|done|fold|ps|kill|note|wait|agent)
                 local -a workspaces
                 workspaces=(${(f)"$(command ws list --quiet 2>/dev/null)"})
                 _describe 'workspace' workspaces
@@ -202,22 +214,21 @@ compdef _ws ws`
 
 var fishIntegration = `# ws - workspace manager
 function ws
-    if test "$argv[1]" = "go" -a -n "$argv[2]"
-        set -l target (command ws go $argv[2] 2>/dev/null)
+    if test "$argv[1]" = "go"
+        # Errors, and the picker when no name is given, go to the terminal
+        set -l target (command ws $argv)
         set -l exit_code $status
         if test $exit_code -eq 0 -a -n "$target" -a -d "$target"
             cd $target
-        else
-            command ws go $argv[2]
-            return $exit_code
         end
+        return $exit_code
     else if test "$argv[1]" = "home"
-        set -l target (command ws home 2>/dev/null)
+        set -l target (command ws $argv 2>/dev/null)
         set -l exit_code $status
         if test $exit_code -eq 0 -a -n "$target" -a -d "$target"
             cd $target
         else
-            command ws home
+            command ws $argv
             return $exit_code
         end
     else if test "$argv[1]" = "new" -a -n "$argv[2]"
@@ -230,15 +241,21 @@ function ws
             end
         end
         return $exit_code
-    else if test "$argv[1]" = "ez" -a -n "$argv[2]"
+    else if test "$argv[1]" = "ez" -o "$argv[1]" = "agent" -o "$argv[1]" = "ui"
+        # ws writes the workspace to cd into, then any agent command to run
+        set -l handoff (mktemp)
+        set -lx WS_HANDOFF $handoff
         command ws $argv
         set -l exit_code $status
-        if test $exit_code -eq 0
-            set -l target (command ws go $argv[2] 2>/dev/null)
-            if test -n "$target" -a -d "$target"
-                cd $target
-                set -l agent_cmd (command ws agent-cmd)
+        set -e WS_HANDOFF
+        set -l target (head -n 1 $handoff)
+        set -l agent_cmd (tail -n +2 $handoff | string collect)
+        rm -f $handoff
+        if test -n "$target" -a -d "$target"
+            cd $target
+            if test $exit_code -eq 0 -a -n "$agent_cmd"
                 eval $agent_cmd
+                command ws hook post-agent
             end
         end
         return $exit_code
@@ -257,6 +274,7 @@ function ws
         if test $exit_code -eq 0
             set -l agent_cmd (command ws agent-cmd)
             eval $agent_cmd "Help me finish this rebase. There are merge conflicts that need to be resolved. Look at the conflicted files, understand both sides, and resolve them appropriately. Ask me any questions if you're unsure about the intent. Once resolved, run 'git add' on the fixed files and 'git rebase --continue'."
+            command ws hook post-agent
         end
         return $exit_code
     else
@@ -267,6 +285,7 @@ end
 # Completion
 complete -c ws -n "__fish_use_subcommand" -a new -d "Create a new workspace"
 complete -c ws -n "__fish_use_subcommand" -a ez -d "Create workspace and start agent"
+complete -c ws -n "__fish_use_subcommand" -a agent -d "Start agent in a workspace"
 complete -c ws -n "__fish_use_subcommand" -a list -d "List all workspaces"
 complete -c ws -n "__fish_use_subcommand" -a go -d "Navigate to a workspace"
 complete -c ws -n "__fish_use_subcommand" -a home -d "Navigate to main repository"
@@ -274,11 +293,16 @@ complete -c ws -n "__fish_use_subcommand" -a done -d "Remove a workspace"
 complete -c ws -n "__fish_use_subcommand" -a fold -d "Rebase and merge workspace"
 complete -c ws -n "__fish_use_subcommand" -a auto-rebase -d "Resolve rebase conflicts with agent"
 complete -c ws -n "__fish_use_subcommand" -a status -d "Show workspace status"
+complete -c ws -n "__fish_use_subcommand" -a ps -d "List processes in workspaces"
+complete -c ws -n "__fish_use_subcommand" -a kill -d "Stop processes in a workspace"
+complete -c ws -n "__fish_use_subcommand" -a note -d "Attach a note to a workspace"
+complete -c ws -n "__fish_use_subcommand" -a ui -d "Open the workspace dashboard"
+complete -c ws -n "__fish_use_subcommand" -a wait -d "Wait for a workspace to reach a condition"
 complete -c ws -n "__fish_use_subcommand" -a prune -d "Clean up stale worktrees"
 complete -c ws -n "__fish_use_subcommand" -a init -d "Set up shell integration"
 complete -c ws -n "__fish_use_subcommand" -a config -d "Manage configuration"
 
-complete -c ws -n "__fish_seen_subcommand_from go done fold" -a "(command ws list --quiet 2>/dev/null)"`
+complete -c ws -n "__fish_seen_subcommand_from go done fold ps kill note wait agent" -a "(command ws list --quiet 2>/dev/null)"`
 
 // InitCmd handles the 'ws init' command.
 func InitCmd(args []string) int {
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/WillCMcC/ws/internal/process"
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+// KillCmd handles the 'ws kill' command.
+func KillCmd(args []string) int {
+	fs := flag.NewFlagSet("kill", flag.ExitOnError)
+	signal := fs.String("signal", "TERM", "Signal to send first")
+	timeout := fs.Duration("timeout", workspace.StopGrace, "How long to wait before sending SIGKILL")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ws kill <name> [--signal SIG] [--timeout DURATION]\n\n")
+		fmt.Fprintf(os.Stderr, "Stop every process running inside a workspace. Processes still\n")
+		fmt.Fprintf(os.Stderr, "running after the timeout are sent SIGKILL.\n\n")
+		fmt.Fprintf(os.Stderr, "Arguments:\n")
+		fmt.Fprintf(os.Stderr, "  name    Workspace whose processes to stop\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "ws: missing workspace name\n")
+		fmt.Fprintf(os.Stderr, "    Usage: ws kill <name>\n")
+		return 1
+	}
+
+	sig, err := process.ParseSignal(*signal)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
+	name := fs.Arg(0)
+
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if err.Error() == "not a git repository" {
+			fmt.Fprintf(os.Stderr, "    Run this command from within a git repository.\n")
+			return 2
+		}
+		return 1
+	}
+
+	ws, code := confirmWorkspace(mgr, name, "stop the processes in")
+	if code != 0 {
+		return code
+	}
+
+	table, err := process.Snapshot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: failed to read process table: %v\n", err)
+		return 1
+	}
+
+	procs := workspace.Processes(table, ws.Path)
+	if len(procs) == 0 {
+		fmt.Printf("No processes running in %s\n", ws.Name)
+		return 0
+	}
+
+	if err := workspace.StopProcesses(procs, sig, *timeout); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -7,8 +7,8 @@ import (
 	"os"
 	"strings"
 	"text/tabwriter"
+	"text/template"
 
-	"github.com/WillCMcC/ws/internal/process"
 	"github.com/WillCMcC/ws/internal/workspace"
 )
 
@@ -17,10 +17,17 @@ func ListCmd(args []string) int {
 	fs := flag.NewFlagSet("list", flag.ExitOnError)
 	jsonOutput := fs.Bool("json", false, "Output as JSON")
 	quiet := fs.Bool("quiet", false, "Just names, one per line")
+	stateFilter := fs.String("state", "", "Only list workspaces in this state")
+	format := fs.String("format", "", "Format each workspace with a Go template")
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ws list [--json] [--quiet]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws list [--json | --quiet | --format TEMPLATE] [--state STATE]\n\n")
 		fmt.Fprintf(os.Stderr, "List all workspaces for the current repository.\n\n")
+		fmt.Fprintf(os.Stderr, "States: running, waiting-for-input, idle, exited, conflicted,\n")
+		fmt.Fprintf(os.Stderr, "dirty-no-agent.\n\n")
+		fmt.Fprintf(os.Stderr, "Templates are executed once per workspace, with the fields of the\n")
+		fmt.Fprintf(os.Stderr, "JSON output and the functions short, ago, truncate and color, e.g.\n")
+		fmt.Fprintf(os.Stderr, "  ws list --format '{{.Name}}\\t{{.Ahead}}\\t{{.State | color \"green\"}}'\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		fs.PrintDefaults()
 	}
@@ -29,6 +36,21 @@ func ListCmd(args []string) int {
 		return 1
 	}
 
+	var filter workspace.State
+	if *stateFilter != "" {
+		state, err := workspace.ParseState(*stateFilter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+			return 1
+		}
+		filter = state
+	}
+
+	tmpl, code := outputTemplate(*format, *jsonOutput, *quiet)
+	if code != 0 {
+		return code
+	}
+
 	mgr, err := workspace.NewManager()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
@@ -45,31 +67,77 @@ func ListCmd(args []string) int {
 		return 1
 	}
 
+	// Names alone don't need the (slower) state classification
+	if *quiet && filter == "" {
+		return outputQuiet(workspaces)
+	}
+
+	classifier := mgr.NewStateClassifier()
+	base := mgr.Config.GetDefaultBase()
+	var infos []workspace.Info
+	for _, info := range classifier.InspectAll(workspaces, base) {
+		if filter == "" || info.State == filter {
+			infos = append(infos, info)
+		}
+	}
+
 	if *jsonOutput {
-		return outputJSON(workspaces)
+		return outputJSON(infos)
 	}
 
-	if *quiet {
-		return outputQuiet(workspaces)
+	if tmpl != nil {
+		return outputFormat(tmpl, infos)
+	}
+
+	if filter != "" && len(infos) == 0 {
+		fmt.Printf("No workspaces are %s.\n", filter)
+		return 0
 	}
 
-	return outputTable(mgr, workspaces)
+	return outputTable(mgr, infos)
 }
 
-func outputJSON(workspaces []workspace.Workspace) int {
-	type wsJSON struct {
-		Name   string `json:"name"`
-		Branch string `json:"branch"`
-		Path   string `json:"path"`
+// outputTemplate returns the template for the --format and --quiet
+// output flags, or nil for the default output. --quiet is shorthand for
+// --format '{{.Name}}'. A nonzero code means the flags were invalid.
+func outputTemplate(format string, jsonOutput, quiet bool) (*template.Template, int) {
+	set := 0
+	for _, on := range []bool{format != "", jsonOutput, quiet} {
+		if on {
+			set++
+		}
+	}
+	if set > 1 {
+		fmt.Fprintf(os.Stderr, "ws: --json, --quiet and --format can't be combined\n")
+		return nil, 1
 	}
 
-	var output []wsJSON
-	for _, ws := range workspaces {
-		output = append(output, wsJSON{
-			Name:   ws.Name,
-			Branch: ws.Branch,
-			Path:   ws.Path,
-		})
+	if quiet {
+		format = "{{.Name}}"
+	}
+	if format == "" {
+		return nil, 0
+	}
+	tmpl, err := parseFormat(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return nil, 1
+	}
+	return tmpl, 0
+}
+
+// outputJSON writes infos as the versioned JSON document shared by
+// 'ws list --json' and 'ws status --json'.
+func outputJSON(infos []workspace.Info) int {
+	output := struct {
+		Version    int              `json:"version"`
+		Workspaces []workspace.Info `json:"workspaces"`
+	}{
+		Version:    workspace.InfoVersion,
+		Workspaces: infos,
+	}
+	if output.Workspaces == nil {
+		output.Workspaces = []workspace.Info{}
 	}
 
 	enc := json.NewEncoder(os.Stdout)
@@ -88,29 +156,27 @@ func outputQuiet(workspaces []workspace.Workspace) int {
 	return 0
 }
 
-func outputTable(mgr *workspace.Manager, workspaces []workspace.Workspace) int {
-	if len(workspaces) == 0 {
+func outputTable(mgr *workspace.Manager, infos []workspace.Info) int {
+	if len(infos) == 0 {
 		fmt.Println("No workspaces found.")
 		fmt.Println()
 		fmt.Println("Create one with: ws new <name>")
 		return 0
 	}
 
-	agentNames := mgr.Config.Status.AgentProcesses
-
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "WORKSPACE\tBRANCH\tPATH\tSTATUS")
+	fmt.Fprintln(w, "WORKSPACE\tBRANCH\tPATH\tSTATE\tAGENT")
 
-	for _, ws := range workspaces {
-		status := "idle"
-		if mgr.Config.Status.DetectProcesses {
-			agents := process.DetectAgents(ws.Path, agentNames)
-			if len(agents) > 0 {
-				status = fmt.Sprintf("%s (pid %d)", agents[0].Name, agents[0].PID)
-			}
+	for _, info := range infos {
+		agent := "-"
+		if len(info.Agents) > 0 {
+			agent = fmt.Sprintf("%s (pid %d)", info.Agents[0].Name, info.Agents[0].PID)
+		}
+		if len(info.Agents) > 1 {
+			agent += fmt.Sprintf(" +%d", len(info.Agents)-1)
 		}
-		path := shortenPath(ws.Path)
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ws.Name, ws.Branch, path, status)
+		path := shortenPath(info.Path)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", info.Name, info.Branch, path, info.State, agent)
 	}
 	w.Flush()
 
@@ -12,7 +12,7 @@ import (
 func NewCmd(args []string) int {
 	fs := flag.NewFlagSet("new", flag.ExitOnError)
 	from := fs.String("from", "", "Base ref to branch from")
-	noHooks := fs.Bool("no-hooks", false, "Skip post-create hooks")
+	noHooks := fs.Bool("no-hooks", false, "Skip create hooks")
 
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: ws new <name> [--from <ref>] [--no-hooks]\n\n")
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+// NoteCmd handles the 'ws note' command.
+func NoteCmd(args []string) int {
+	fs := flag.NewFlagSet("note", flag.ExitOnError)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ws note <name> [text...]\n\n")
+		fmt.Fprintf(os.Stderr, "Attach a note to a workspace, or list its notes when no text is given.\n\n")
+		fmt.Fprintf(os.Stderr, "Arguments:\n")
+		fmt.Fprintf(os.Stderr, "  name    Workspace to annotate\n")
+		fmt.Fprintf(os.Stderr, "  text    Note to attach\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "ws: missing workspace name\n")
+		fmt.Fprintf(os.Stderr, "    Usage: ws note <name> [text...]\n")
+		return 1
+	}
+
+	name := fs.Arg(0)
+	text := strings.TrimSpace(strings.Join(fs.Args()[1:], " "))
+
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if err.Error() == "not a git repository" {
+			fmt.Fprintf(os.Stderr, "    Run this command from within a git repository.\n")
+			return 2
+		}
+		return 1
+	}
+
+	ws, code := resolveWorkspace(mgr, name)
+	if code != 0 {
+		return code
+	}
+
+	if text == "" {
+		if len(ws.Meta.Notes) == 0 {
+			fmt.Printf("No notes on %s\n", ws.Name)
+		}
+		for _, note := range ws.Meta.Notes {
+			fmt.Printf("%s  %s\n", note.Time.Format("2006-01-02 15:04"), note.Text)
+		}
+		return 0
+	}
+
+	if err := mgr.AddNote(ws.Name, text); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: failed to save note: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/WillCMcC/ws/internal/workspace"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pickerRows is how many workspaces the picker shows at once.
+const pickerRows = 10
+
+// pickWorkspace lets the user choose a workspace with a fuzzy finder drawn
+// on stderr, so stdout stays free for the command's own output. It returns
+// "" if they cancel.
+func pickWorkspace(mgr *workspace.Manager, prompt string) (string, error) {
+	workspaces, err := mgr.List()
+	if err != nil {
+		return "", fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	if len(workspaces) == 0 {
+		return "", fmt.Errorf("no workspaces found")
+	}
+
+	// Stdout is usually captured by the shell wrapper, so pick colors for
+	// the terminal the picker is drawn on
+	lipgloss.SetColorProfile(lipgloss.NewRenderer(os.Stderr).ColorProfile())
+
+	m := pickerModel{
+		mgr:        mgr,
+		prompt:     prompt,
+		workspaces: workspaces,
+	}
+	m.match()
+
+	final, err := tea.NewProgram(m, tea.WithOutput(os.Stderr)).Run()
+	if err != nil {
+		return "", err
+	}
+	return final.(pickerModel).chosen, nil
+}
+
+// pickTarget picks the workspace for a command run without one. A nonzero
+// code means there is nothing to act on: the picker failed or was
+// cancelled.
+func pickTarget(mgr *workspace.Manager, prompt string) (string, int) {
+	name, err := pickWorkspace(mgr, prompt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return "", 1
+	}
+	if name == "" {
+		return "", 1
+	}
+	return name, 0
+}
+
+// pickerModel is the state of the workspace picker.
+type pickerModel struct {
+	mgr        *workspace.Manager
+	prompt     string
+	workspaces []workspace.Workspace
+	infos      map[string]workspace.Info // Filled in once inspected
+
+	filter  string
+	matches []int // Indices of the workspaces matching filter, best first
+	cursor  int
+	offset  int
+	chosen  string
+	done    bool
+}
+
+// pickerInfosMsg carries the inspected workspaces.
+type pickerInfosMsg []workspace.Info
+
+func (m pickerModel) Init() tea.Cmd {
+	mgr, workspaces := m.mgr, m.workspaces
+	return func() tea.Msg {
+		base := mgr.Config.GetDefaultBase()
+		return pickerInfosMsg(mgr.NewStateClassifier().InspectAll(workspaces, base))
+	}
+}
+
+func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pickerInfosMsg:
+		m.infos = make(map[string]workspace.Info)
+		for _, info := range msg {
+			m.infos[info.Name] = info
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			m.done = true
+			return m, tea.Quit
+		case "enter":
+			if m.cursor < len(m.matches) {
+				m.chosen = m.workspaces[m.matches[m.cursor]].Name
+				m.done = true
+				return m, tea.Quit
+			}
+		case "up", "ctrl+p", "ctrl+k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "ctrl+n", "ctrl+j", "tab":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+		case "backspace", "ctrl+h":
+			if m.filter != "" {
+				_, size := lastRune(m.filter)
+				m.filter = m.filter[:len(m.filter)-size]
+				m.match()
+			}
+		case "ctrl+u":
+			m.filter = ""
+			m.match()
+		default:
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				m.filter += string(msg.Runes)
+				m.match()
+			}
+		}
+	}
+
+	// Keep the cursor in view
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+pickerRows {
+		m.offset = m.cursor - pickerRows + 1
+	}
+	return m, nil
+}
+
+// match finds the workspaces matching the filter, best match first, and
+// moves the cursor back to the top.
+func (m *pickerModel) match() {
+	scores := make(map[int]int)
+	m.matches = m.matches[:0]
+	for i, ws := range m.workspaces {
+		score, ok := workspace.FuzzyScore(m.filter, ws.Name)
+		if branchScore, branchOK := workspace.FuzzyScore(m.filter, ws.Branch); branchOK && (!ok || branchScore > score) {
+			score, ok = branchScore, true
+		}
+		if ok {
+			scores[i] = score
+			m.matches = append(m.matches, i)
+		}
+	}
+	if m.filter != "" {
+		sort.SliceStable(m.matches, func(a, b int) bool {
+			return scores[m.matches[a]] > scores[m.matches[b]]
+		})
+	}
+	m.cursor, m.offset = 0, 0
+}
+
+func (m pickerModel) View() string {
+	if m.done {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(promptStyle.Render(m.prompt+"> ") + inputStyle.Render(m.filter) + "█\n")
+
+	nameWidth, branchWidth := 0, 0
+	for _, i := range m.matches {
+		nameWidth = max(nameWidth, lipgloss.Width(m.workspaces[i].Name))
+		branchWidth = max(branchWidth, lipgloss.Width(m.workspaces[i].Branch))
+	}
+
+	end := min(m.offset+pickerRows, len(m.matches))
+	for row := m.offset; row < end; row++ {
+		ws := m.workspaces[m.matches[row]]
+
+		cursor, style := "  ", lipgloss.NewStyle()
+		if row == m.cursor {
+			cursor, style = "▸ ", style.Bold(true).Foreground(lipgloss.Color("229"))
+		}
+		b.WriteString(cursor)
+		b.WriteString(style.Render(fmt.Sprintf("%-*s", nameWidth, ws.Name)))
+		b.WriteString("  ")
+		b.WriteString(valueStyle.Render(fmt.Sprintf("%-*s", branchWidth, ws.Branch)))
+
+		if info, ok := m.infos[ws.Name]; ok {
+			state := string(info.State)
+			if len(info.Agents) > 0 {
+				state += " · " + info.Agents[0].Name
+			}
+			b.WriteString("  ")
+			b.WriteString(uiStateStyles[info.State].Render(state))
+		} else if m.infos == nil {
+			b.WriteString(valueStyle.Render("  …"))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(valueStyle.Render(fmt.Sprintf("  %d/%d", len(m.matches), len(m.workspaces))))
+	b.WriteString(helpStyle.UnsetMarginTop().Render("  ↑/↓: select • enter: choose • esc: cancel"))
+	return b.String()
+}
+
+// lastRune returns the last rune of s and its size in bytes.
+func lastRune(s string) (rune, int) {
+	r := []rune(s)
+	last := r[len(r)-1]
+	return last, len(string(last))
+}
@@ -54,6 +54,19 @@ func PruneCmd(args []string) int {
 		fmt.Println("Would prune git worktree metadata")
 	}
 
+	// Drop what ws recorded about workspaces that are gone
+	stale, err := mgr.PruneMeta(*dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to prune workspace metadata: %v\n", err)
+	}
+	for _, name := range stale {
+		if *dryRun {
+			fmt.Printf("Would remove metadata of removed workspace %s\n", name)
+		} else {
+			fmt.Printf("Removed metadata of removed workspace %s\n", name)
+		}
+	}
+
 	// Find orphaned directories in workspace directory
 	wsDir := mgr.Config.GetWorkspaceDir(mgr.RepoRoot)
 	entries, err := os.ReadDir(wsDir)
@@ -85,7 +98,7 @@ func PruneCmd(args []string) int {
 			continue
 		}
 		path := fmt.Sprintf("%s/%s", wsDir, entry.Name())
-		if !validPaths[path] {
+		if !validPaths[path] && !holdsWorktree(path, validPaths) {
 			orphans = append(orphans, path)
 		}
 	}
@@ -136,3 +149,14 @@ func PruneCmd(args []string) int {
 
 	return 0
 }
+
+// holdsWorktree reports whether dir is a parent of a worktree, as with
+// the feature/ directory of a workspace named feature/x.
+func holdsWorktree(dir string, worktrees map[string]bool) bool {
+	for path := range worktrees {
+		if strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
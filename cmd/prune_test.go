@@ -0,0 +1,24 @@
+package cmd
+
+import "testing"
+
+func TestHoldsWorktree(t *testing.T) {
+	worktrees := map[string]bool{
+		"/repo/.worktrees/repo/feature/login": true,
+		"/repo/.worktrees/repo/fix":           true,
+	}
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{"/repo/.worktrees/repo/feature", true},
+		{"/repo/.worktrees/repo/fix", false}, // A worktree, not a parent of one
+		{"/repo/.worktrees/repo/feat", false},
+		{"/repo/.worktrees/repo/stale", false},
+	}
+	for _, tt := range tests {
+		if got := holdsWorktree(tt.dir, worktrees); got != tt.want {
+			t.Errorf("holdsWorktree(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+}
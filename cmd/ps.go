@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/process"
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+// PsCmd handles the 'ws ps' command.
+func PsCmd(args []string) int {
+	fs := flag.NewFlagSet("ps", flag.ExitOnError)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ws ps [name]\n\n")
+		fmt.Fprintf(os.Stderr, "List the processes running inside a workspace, or all workspaces.\n\n")
+		fmt.Fprintf(os.Stderr, "Arguments:\n")
+		fmt.Fprintf(os.Stderr, "  name    Workspace to inspect (default: all)\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if err.Error() == "not a git repository" {
+			fmt.Fprintf(os.Stderr, "    Run this command from within a git repository.\n")
+			return 2
+		}
+		return 1
+	}
+
+	var workspaces []workspace.Workspace
+	if fs.NArg() > 0 {
+		ws, code := resolveWorkspace(mgr, fs.Arg(0))
+		if code != 0 {
+			return code
+		}
+		workspaces = []workspace.Workspace{*ws}
+	} else {
+		workspaces, err = mgr.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ws: failed to list workspaces: %v\n", err)
+			return 1
+		}
+	}
+
+	table, err := process.Snapshot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: failed to read process table: %v\n", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKSPACE\tPID\tCPU\tRSS\tUPTIME\tCOMMAND")
+	found := 0
+	for _, ws := range workspaces {
+		for _, p := range workspace.Processes(table, ws.Path) {
+			fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%s\t%s\t%s\n",
+				ws.Name, p.PID, p.CPUPercent(), formatMemory(p.RSS), formatUptime(p.Uptime()), commandLine(p, 60))
+			found++
+		}
+	}
+
+	if found == 0 {
+		fmt.Println("No processes running in workspaces.")
+		return 0
+	}
+	w.Flush()
+	return 0
+}
+
+// commandLine returns the process's command line, truncated to max
+// columns.
+func commandLine(p process.Process, max int) string {
+	return truncate(p.CommandLine(), max)
+}
+
+// formatMemory formats a byte count in the largest whole unit, e.g. "84M".
+func formatMemory(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%dM", n>>20)
+	default:
+		return fmt.Sprintf("%dK", n>>10)
+	}
+}
+
+// formatUptime formats a duration with its two largest units, e.g. "3h12m".
+func formatUptime(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int(d / (24 * time.Hour))
+	hours := int(d/time.Hour) % 24
+	minutes := int(d/time.Minute) % 60
+	seconds := int(d/time.Second) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm%ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+// resolveWorkspace resolves a workspace name given on the command line,
+// which may be a unique prefix, a fuzzy match or "-" for the previous
+// workspace, and explains why when it can't. A nonzero code is the exit
+// code to return: 3 when no workspace matches.
+func resolveWorkspace(mgr *workspace.Manager, name string) (*workspace.Workspace, int) {
+	ws, err := mgr.Resolve(name)
+	if err != nil {
+		return nil, lookupFailed(err, name)
+	}
+	return ws, 0
+}
+
+// confirmWorkspace is resolveWorkspace for commands that destroy work in
+// the workspace, described by action ("remove", "fold"). A name other than
+// the workspace's own must be confirmed on a terminal, and is refused
+// without one, so that a short name can't hit a workspace by surprise.
+func confirmWorkspace(mgr *workspace.Manager, name, action string) (*workspace.Workspace, int) {
+	ws, code := resolveWorkspace(mgr, name)
+	if code != 0 || ws.Name == name {
+		return ws, code
+	}
+
+	if !interactive() {
+		fmt.Fprintf(os.Stderr, "ws: '%s' refers to workspace '%s'\n", name, ws.Name)
+		fmt.Fprintf(os.Stderr, "    Give its full name to %s it.\n", action)
+		return nil, 1
+	}
+	fmt.Fprintf(os.Stderr, "'%s' refers to workspace '%s'. %s it? [y/N] ", name, ws.Name, strings.ToUpper(action[:1])+action[1:])
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return nil, 1
+	}
+	return ws, 0
+}
+
+// lookupFailed explains why name didn't lead to a workspace and returns
+// the exit code.
+func lookupFailed(err error, name string) int {
+	var notFound *workspace.NotFoundError
+	var ambiguous *workspace.AmbiguousError
+	switch {
+	case errors.As(err, &notFound):
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if len(notFound.Available) > 0 {
+			fmt.Fprintf(os.Stderr, "    Available workspaces:\n")
+			for _, available := range notFound.Available {
+				fmt.Fprintf(os.Stderr, "      %s\n", available)
+			}
+		}
+		return 3
+	case errors.As(err, &ambiguous):
+		fmt.Fprintf(os.Stderr, "ws: '%s' matches %d workspaces:\n", name, len(ambiguous.Candidates))
+		for _, candidate := range ambiguous.Candidates {
+			fmt.Fprintf(os.Stderr, "      %s\n", candidate)
+		}
+		fmt.Fprintf(os.Stderr, "    Give more of the name to pick one.\n")
+		return 1
+	default:
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+}
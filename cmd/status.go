@@ -1,28 +1,39 @@
 package cmd
 
 import (
+	"cmp"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
-	"github.com/WillCMcC/ws/internal/git"
-	"github.com/WillCMcC/ws/internal/process"
 	"github.com/WillCMcC/ws/internal/workspace"
 )
 
 // StatusCmd handles the 'ws status' command.
 func StatusCmd(args []string) int {
 	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	format := fs.String("format", "", "Format each workspace with a Go template")
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ws status\n\n")
-		fmt.Fprintf(os.Stderr, "Show detailed status of all workspaces.\n")
+		fmt.Fprintf(os.Stderr, "Usage: ws status [--json | --format TEMPLATE]\n\n")
+		fmt.Fprintf(os.Stderr, "Show detailed status of all workspaces.\n\n")
+		fmt.Fprintf(os.Stderr, "Templates are executed once per workspace; see 'ws list --help'.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
 	}
 
 	if err := fs.Parse(args); err != nil {
 		return 1
 	}
 
+	tmpl, code := outputTemplate(*format, *jsonOutput, false)
+	if code != 0 {
+		return code
+	}
+
 	mgr, err := workspace.NewManager()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
@@ -39,58 +50,112 @@ func StatusCmd(args []string) int {
 		return 1
 	}
 
-	if len(workspaces) == 0 {
+	defaultBase := mgr.Config.GetDefaultBase()
+	classifier := mgr.NewStateClassifier()
+
+	infos := classifier.InspectAll(workspaces, defaultBase)
+
+	if *jsonOutput {
+		return outputJSON(infos)
+	}
+	if tmpl != nil {
+		return outputFormat(tmpl, infos)
+	}
+
+	if len(infos) == 0 {
 		fmt.Println("No workspaces found.")
 		fmt.Println()
 		fmt.Println("Create one with: ws new <name>")
 		return 0
 	}
 
-	defaultBase := mgr.Config.GetDefaultBase()
-	agentNames := mgr.Config.Status.AgentProcesses
-
-	for i, ws := range workspaces {
+	for i, info := range infos {
 		if i > 0 {
 			fmt.Println()
 		}
-		fmt.Printf("%s\n", ws.Name)
-		fmt.Printf("  Path:     %s\n", shortenPath(ws.Path))
-		fmt.Printf("  Branch:   %s", ws.Branch)
-
-		// Show commits ahead
-		if ahead, err := git.GetCommitsAhead(ws.Path, defaultBase); err == nil && ahead > 0 {
-			fmt.Printf(" (%d commits ahead of %s)", ahead, defaultBase)
+		fmt.Printf("%s\n", info.Name)
+		fmt.Printf("  State:    %s\n", info.State)
+		if info.Error != "" {
+			fmt.Printf("  Error:    %s\n", info.Error)
+		}
+		fmt.Printf("  Path:     %s\n", shortenPath(info.Path))
+		fmt.Printf("  Branch:   %s", info.Branch)
+
+		// Show commits ahead and behind
+		switch {
+		case info.Ahead > 0 && info.Behind > 0:
+			fmt.Printf(" (%d commits ahead of %s, %d behind)", info.Ahead, info.Base, info.Behind)
+		case info.Ahead > 0:
+			fmt.Printf(" (%d commits ahead of %s)", info.Ahead, info.Base)
+		case info.Behind > 0:
+			fmt.Printf(" (%d commits behind %s)", info.Behind, info.Base)
 		}
 		fmt.Println()
 
 		// Show status
-		hasChanges, status, err := git.HasUncommittedChanges(ws.Path)
-		if err == nil {
-			if hasChanges {
-				lines := countLines(status)
-				fmt.Printf("  Status:   %d file(s) modified\n", lines)
-			} else {
-				fmt.Printf("  Status:   clean\n")
-			}
+		if info.Dirty > 0 {
+			fmt.Printf("  Status:   %d file(s) modified\n", info.Dirty)
+		} else {
+			fmt.Printf("  Status:   clean\n")
+		}
+		if info.Operation != "" {
+			fmt.Printf("  Git:      %s in progress\n", info.Operation)
+		}
+		if info.Locked {
+			fmt.Printf("  Locked:   %s\n", cmp.Or(info.LockReason, "yes"))
 		}
 
 		// Show last commit
-		if msg, when, err := git.GetLastCommit(ws.Path); err == nil {
-			// Truncate message if too long
-			if len(msg) > 40 {
-				msg = msg[:37] + "..."
+		if c := info.LastCommit; !c.IsZero() {
+			fmt.Printf("  Last:     \"%s\" (%s)\n", truncate(c.Subject, 40), formatAgo(c.Time))
+		}
+
+		// Show where the workspace came from, for those ws created
+		meta := info.Meta
+		if !meta.Created.IsZero() {
+			fmt.Printf("  Created:  %s ago", formatUptime(time.Since(meta.Created)))
+			if meta.Creator != "" {
+				fmt.Printf(" by %s", meta.Creator)
+			}
+			fmt.Printf(" from %s", meta.BaseRef)
+			if len(meta.BaseCommit) >= 7 {
+				fmt.Printf(" (%s)", meta.BaseCommit[:7])
+			}
+			fmt.Println()
+		}
+
+		// Show the task the agent was started with
+		if meta.Prompt != "" {
+			fmt.Printf("  Prompt:   %s\n", firstLine(meta.Prompt, 50))
+		}
+
+		// Show the latest note
+		if n := len(meta.Notes); n > 0 {
+			fmt.Printf("  Note:     %s", firstLine(meta.Notes[n-1].Text, 50))
+			if n > 1 {
+				fmt.Printf(" (+%d more)", n-1)
 			}
-			fmt.Printf("  Last:     \"%s\" (%s)\n", msg, when)
+			fmt.Println()
 		}
 
 		// Process detection
-		if mgr.Config.Status.DetectProcesses {
-			agents := process.DetectAgents(ws.Path, agentNames)
-			if len(agents) > 0 {
-				fmt.Printf("  Process:  %s (pid %d)\n", agents[0].Name, agents[0].PID)
-			} else {
+		if classifier.Detecting() {
+			if len(info.Agents) == 0 {
 				fmt.Printf("  Process:  none detected\n")
 			}
+			for j, agent := range info.Agents {
+				label := "  Process:"
+				if j > 0 {
+					label = "          "
+				}
+				fmt.Printf("%s  %s (pid %d)", label, agent.Name, agent.PID)
+				if n := len(agent.Children); n > 0 {
+					fmt.Printf(", %d child process(es)", n)
+				}
+				fmt.Println()
+			}
+		} else if mgr.Config.Status.DetectProcesses {
+			fmt.Printf("  Process:  unavailable\n")
 		} else {
 			fmt.Printf("  Process:  detection disabled\n")
 		}
@@ -99,19 +164,36 @@ func StatusCmd(args []string) int {
 	return 0
 }
 
-func countLines(s string) int {
-	if s == "" {
-		return 0
-	}
-	count := 0
-	for _, c := range s {
-		if c == '\n' {
-			count++
+// firstLine returns the first line of s, truncated to max columns.
+func firstLine(s string, max int) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return truncate(line, max)
+}
+
+// formatAgo describes how long ago t was, the way git does, e.g.
+// "3 hours ago".
+func formatAgo(t time.Time) string {
+	d := time.Since(t)
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
 		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
 	}
-	// Count last line if no trailing newline
-	if len(s) > 0 && s[len(s)-1] != '\n' {
-		count++
+
+	switch {
+	case d < time.Minute:
+		return plural(int(d/time.Second), "second")
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	case d < 14*24*time.Hour:
+		return plural(int(d/(24*time.Hour)), "day")
+	case d < 60*24*time.Hour:
+		return plural(int(d/(7*24*time.Hour)), "week")
+	case d < 365*24*time.Hour:
+		return plural(int(d/(30*24*time.Hour)), "month")
 	}
-	return count
+	return plural(int(d/(365*24*time.Hour)), "year")
 }
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+// TrustCmd handles the 'ws trust' command.
+func TrustCmd(args []string) int {
+	fs := flag.NewFlagSet("trust", flag.ExitOnError)
+	list := fs.Bool("list", false, "Show repository hooks and agent commands and whether they are trusted")
+	revoke := fs.Bool("revoke", false, "Revoke trust for all of this repository's hooks and agent commands")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ws trust [--list] [--revoke]\n\n")
+		fmt.Fprintf(os.Stderr, "Approve the hooks and agent commands this repository provides.\n\n")
+		fmt.Fprintf(os.Stderr, "Hook scripts in %s/, and hook commands, agent_cmd and\n", workspace.RepoHooksDir)
+		fmt.Fprintf(os.Stderr, "agent_prompt_template set in the repository's .ws/config, only run\n")
+		fmt.Fprintf(os.Stderr, "once trusted. Approval is tied to their content, so a changed hook\n")
+		fmt.Fprintf(os.Stderr, "or command must be trusted again.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if err.Error() == "not a git repository" {
+			fmt.Fprintf(os.Stderr, "    Run this command from within a git repository.\n")
+			return 2
+		}
+		return 1
+	}
+
+	store, err := workspace.LoadTrustStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: failed to read trust store: %v\n", err)
+		return 1
+	}
+
+	if *revoke {
+		count := store.Revoke(mgr.RepoRoot)
+		if err := store.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "ws: failed to save trust store: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Revoked %d approval(s) for %s\n", count, shortenPath(mgr.RepoRoot))
+		return 0
+	}
+
+	// Everything the repository provides that needs approval: hooks by
+	// event, then agent commands by config key
+	var items []trustItem
+	for _, event := range workspace.Events {
+		for _, source := range mgr.HookSources(event) {
+			if source.FromRepo {
+				items = append(items, trustItem{string(source.Event), source.Label, source})
+			}
+		}
+	}
+	for _, setting := range mgr.RepoAgentSettings() {
+		items = append(items, trustItem{setting.Key, setting.Label, setting})
+	}
+
+	if len(items) == 0 {
+		fmt.Println("This repository provides no hooks or agent commands.")
+		return 0
+	}
+
+	if *list {
+		for _, item := range items {
+			state := "untrusted"
+			if store.Trusted(mgr.RepoRoot, item.Trustable) {
+				state = "trusted"
+			}
+			fmt.Printf("%-9s  %-21s  %s\n", state, item.kind, item.label)
+		}
+		return 0
+	}
+
+	trusted := 0
+	for _, item := range items {
+		if store.Trusted(mgr.RepoRoot, item.Trustable) {
+			continue
+		}
+		fmt.Printf("%s (%s):\n", item.kind, item.label)
+		for _, line := range strings.Split(strings.TrimRight(string(item.Content()), "\n"), "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+		fmt.Println()
+		store.Trust(mgr.RepoRoot, item.Trustable)
+		trusted++
+	}
+
+	if trusted == 0 {
+		fmt.Printf("All %d hook(s) and agent command(s) for %s are already trusted.\n", len(items), shortenPath(mgr.RepoRoot))
+		return 0
+	}
+
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "ws: failed to save trust store: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Trusted %d hook(s) and agent command(s) for %s\n", trusted, shortenPath(mgr.RepoRoot))
+	return 0
+}
+
+// trustItem is a hook or agent command the repository provides, with the
+// event or config key it is for.
+type trustItem struct {
+	kind  string
+	label string
+	workspace.Trustable
+}
@@ -0,0 +1,559 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/git"
+	"github.com/WillCMcC/ws/internal/workspace"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+)
+
+// UICmd handles the 'ws ui' command.
+func UICmd(args []string) int {
+	fs := flag.NewFlagSet("ui", flag.ExitOnError)
+	interval := fs.Duration("interval", 2*time.Second, "How often to refresh")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ws ui [--interval DURATION]\n\n")
+		fmt.Fprintf(os.Stderr, "Open a live dashboard of every workspace: state, branch, commits\n")
+		fmt.Fprintf(os.Stderr, "ahead and behind, uncommitted changes, agents and last commit.\n\n")
+		fmt.Fprintf(os.Stderr, "Keys:\n")
+		fmt.Fprintf(os.Stderr, "  enter   Go to the workspace (with shell integration) and quit\n")
+		fmt.Fprintf(os.Stderr, "  a       Start an agent (in a new tmux window inside tmux)\n")
+		fmt.Fprintf(os.Stderr, "  d       Show the diff against the base branch\n")
+		fmt.Fprintf(os.Stderr, "  c       Show the commits since the base branch\n")
+		fmt.Fprintf(os.Stderr, "  l       Follow the agent log\n")
+		fmt.Fprintf(os.Stderr, "  f       Fold the workspace\n")
+		fmt.Fprintf(os.Stderr, "  x       Remove the workspace\n")
+		fmt.Fprintf(os.Stderr, "  r       Refresh now\n")
+		fmt.Fprintf(os.Stderr, "  q       Quit\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if *interval <= 0 {
+		fmt.Fprintf(os.Stderr, "ws: --interval must be positive\n")
+		return 1
+	}
+
+	// The dashboard is drawn on stderr, leaving stdout for the path to go to
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stderr.Fd()) {
+		fmt.Fprintf(os.Stderr, "ws: ws ui needs a terminal\n")
+		return 1
+	}
+
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if err.Error() == "not a git repository" {
+			fmt.Fprintf(os.Stderr, "    Run this command from within a git repository.\n")
+			return 2
+		}
+		return 1
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		return 1
+	}
+
+	m := uiModel{
+		mgr:      mgr,
+		exe:      exe,
+		base:     mgr.Config.GetDefaultBase(),
+		repo:     git.RepoName(mgr.RepoRoot),
+		interval: *interval,
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	target := final.(uiModel).target
+	if target == "" {
+		return 0
+	}
+	if handoff := os.Getenv(handoffEnv); handoff != "" {
+		return writeHandoff(handoff, target, "")
+	}
+	fmt.Println(target)
+	return 0
+}
+
+// Dashboard styles, on top of those of the config editor
+var (
+	uiHeaderStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("243")).
+			Bold(true)
+
+	uiDetailStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("246")).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("238")).
+			Padding(0, 1).
+			MarginTop(1)
+
+	uiStateStyles = map[workspace.State]lipgloss.Style{
+		workspace.StateRunning:      lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+		workspace.StateWaiting:      lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true),
+		workspace.StateExited:       lipgloss.NewStyle().Foreground(lipgloss.Color("75")),
+		workspace.StateConflicted:   lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true),
+		workspace.StateDirtyNoAgent: lipgloss.NewStyle().Foreground(lipgloss.Color("178")),
+		workspace.StateIdle:         lipgloss.NewStyle().Foreground(lipgloss.Color("243")),
+		workspace.StateUnknown:      lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+	}
+)
+
+// uiModel is the state of the 'ws ui' dashboard.
+type uiModel struct {
+	mgr      *workspace.Manager
+	exe      string // The ws binary, for running actions
+	base     string
+	repo     string
+	interval time.Duration
+
+	infos      []workspace.Info
+	cursor     int
+	offset     int // First row shown when the list is taller than the screen
+	refreshed  time.Time
+	refreshing bool
+	ticking    bool // A tick is scheduled
+	width      int
+	height     int
+
+	confirm string // Action awaiting confirmation: "fold" or "done"
+	message string
+	failed  bool
+	target  string // Workspace to go to once the dashboard exits
+}
+
+// uiInfosMsg carries the result of a refresh.
+type uiInfosMsg struct {
+	infos []workspace.Info
+	err   error
+}
+
+// uiTickMsg asks for a refresh.
+type uiTickMsg struct{}
+
+// uiDoneMsg reports that an action run outside the dashboard finished.
+type uiDoneMsg struct {
+	action string
+	name   string
+	err    error
+}
+
+func (m uiModel) Init() tea.Cmd {
+	return m.refresh()
+}
+
+// refresh inspects every workspace in the background.
+func (m uiModel) refresh() tea.Cmd {
+	mgr, base := m.mgr, m.base
+	return func() tea.Msg {
+		workspaces, err := mgr.List()
+		if err != nil {
+			return uiInfosMsg{err: err}
+		}
+		return uiInfosMsg{infos: mgr.NewStateClassifier().InspectAll(workspaces, base)}
+	}
+}
+
+// tick schedules the next refresh, unless one is already scheduled.
+func (m *uiModel) tick() tea.Cmd {
+	if m.ticking {
+		return nil
+	}
+	m.ticking = true
+	return tea.Tick(m.interval, func(time.Time) tea.Msg { return uiTickMsg{} })
+}
+
+func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+		return m, nil
+
+	case uiInfosMsg:
+		m.refreshing = false
+		if msg.err != nil {
+			m.message, m.failed = fmt.Sprintf("Failed to list workspaces: %v", msg.err), true
+			return m, m.tick()
+		}
+		m.setInfos(msg.infos)
+		return m, m.tick()
+
+	case uiTickMsg:
+		m.ticking = false
+		if m.refreshing {
+			return m, nil
+		}
+		m.refreshing = true
+		return m, m.refresh()
+
+	case uiDoneMsg:
+		if msg.err != nil {
+			m.message, m.failed = fmt.Sprintf("%s %s failed: %v", msg.action, msg.name, msg.err), true
+		} else {
+			m.message, m.failed = fmt.Sprintf("%s %s finished", msg.action, msg.name), false
+		}
+		if m.refreshing {
+			return m, nil
+		}
+		m.refreshing = true
+		return m, m.refresh()
+
+	case tea.KeyMsg:
+		if m.confirm != "" {
+			return m.handleConfirmInput(msg)
+		}
+		return m.handleDashboardInput(msg)
+	}
+	return m, nil
+}
+
+// setInfos replaces the workspaces shown, keeping the same one selected.
+func (m *uiModel) setInfos(infos []workspace.Info) {
+	selected := ""
+	if info, ok := m.selected(); ok {
+		selected = info.Name
+	}
+
+	m.infos = infos
+	m.refreshed = time.Now()
+	for i, info := range infos {
+		if info.Name == selected {
+			m.cursor = i
+		}
+	}
+	m.cursor = min(m.cursor, max(len(infos)-1, 0))
+	m.scroll()
+}
+
+// selected returns the workspace under the cursor.
+func (m uiModel) selected() (workspace.Info, bool) {
+	if m.cursor >= len(m.infos) {
+		return workspace.Info{}, false
+	}
+	return m.infos[m.cursor], true
+}
+
+func (m uiModel) handleDashboardInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.scroll()
+		return m, nil
+	case "down", "j":
+		if m.cursor < len(m.infos)-1 {
+			m.cursor++
+		}
+		m.scroll()
+		return m, nil
+	case "r":
+		m.message = ""
+		if m.refreshing {
+			return m, nil
+		}
+		m.refreshing = true
+		return m, m.refresh()
+	}
+
+	info, ok := m.selected()
+	if !ok {
+		return m, nil
+	}
+	m.message, m.failed = "", false
+
+	switch msg.String() {
+	case "enter", "g":
+		_ = m.mgr.RecordVisit(info.Name) // Only needed for 'ws go -'
+		m.target = info.Path
+		return m, tea.Quit
+	case "a":
+		return m, m.startAgent(info)
+	case "d":
+		return m, m.page("diff", info, "-C", info.Path, "diff", "--merge-base", m.base)
+	case "c":
+		return m, m.page("commits", info, "-C", info.Path, "log", "--stat", m.base+"..HEAD")
+	case "l":
+		path, err := m.mgr.AgentLogPath(info.Name)
+		if err == nil {
+			_, err = os.Stat(path)
+		}
+		if err != nil {
+			m.message, m.failed = fmt.Sprintf("No agent log for %s; agents started with a are logged", info.Name), true
+			return m, nil
+		}
+		return m, m.follow("log", info, path)
+	case "f":
+		m.confirm = "fold"
+	case "x":
+		m.confirm = "done"
+	}
+	return m, nil
+}
+
+func (m uiModel) handleConfirmInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action := m.confirm
+	m.confirm = ""
+
+	info, ok := m.selected()
+	if !ok || (msg.String() != "y" && msg.String() != "Y") {
+		return m, nil
+	}
+	return m, m.runWs(action, info, action, info.Name)
+}
+
+// scroll moves the visible rows so the cursor stays on screen.
+func (m *uiModel) scroll() {
+	rows := m.visibleRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.infos)-rows))
+}
+
+// visibleRows returns how many workspace rows fit on screen, leaving room
+// for the title, header, details and help.
+func (m uiModel) visibleRows() int {
+	if m.height == 0 {
+		return len(m.infos)
+	}
+	return max(m.height-14, 3)
+}
+
+// runWs runs ws with args for the selected workspace, taking over the
+// terminal until the user has read its output.
+func (m uiModel) runWs(action string, info workspace.Info, args ...string) tea.Cmd {
+	// "$0" "$@" runs the command without any quoting of its arguments
+	script := `"$0" "$@"; code=$?; printf '\n[exit %d] Press enter to return to ws ui ' "$code"; read -r _`
+	cmd := exec.Command("sh", append([]string{"-c", script, m.exe}, args...)...)
+	cmd.Dir = m.mgr.RepoRoot
+	cmd.Env = uiEnv()
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return uiDoneMsg{action: action, name: info.Name, err: err}
+	})
+}
+
+// page shows the output of a git command in the pager.
+func (m uiModel) page(action string, info workspace.Info, args ...string) tea.Cmd {
+	cmd := exec.Command("git", args...)
+	// Keep less open even when everything fits on one screen
+	cmd.Env = append(uiEnv(), "LESS=R")
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return uiDoneMsg{action: action, name: info.Name, err: err}
+	})
+}
+
+// follow shows the end of the file at path as it grows, until Ctrl-C.
+func (m uiModel) follow(action string, info workspace.Info, path string) tea.Cmd {
+	// Ctrl-C stops tail, not the script, so returning isn't a failure
+	script := `trap : INT; printf 'Following %s (Ctrl-C to return to ws ui)\n\n' "$0"; tail -n 100 -f "$0"; exit 0`
+	cmd := exec.Command("sh", "-c", script, path)
+	cmd.Env = uiEnv()
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return uiDoneMsg{action: action, name: info.Name, err: err}
+	})
+}
+
+// startAgent starts an agent in the selected workspace: in a new window
+// when running inside tmux, so the dashboard stays up, and in the
+// foreground otherwise.
+func (m uiModel) startAgent(info workspace.Info) tea.Cmd {
+	if os.Getenv("TMUX") != "" {
+		cmd := exec.Command("tmux", "new-window", "-c", info.Path, "-n", info.Name,
+			m.exe, "agent", "--launch", "native", info.Name)
+		cmd.Env = uiEnv()
+		return func() tea.Msg {
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				err = fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+			}
+			return uiDoneMsg{action: "agent", name: info.Name, err: err}
+		}
+	}
+
+	cmd := exec.Command(m.exe, "agent", "--launch", "native", info.Name)
+	cmd.Dir = info.Path
+	cmd.Env = uiEnv()
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return uiDoneMsg{action: "agent", name: info.Name, err: err}
+	})
+}
+
+// uiEnv returns the environment for commands run from the dashboard.
+// The shell integration's handoff file is meant for ws ui itself.
+func uiEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, handoffEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+func (m uiModel) View() string {
+	var b strings.Builder
+
+	title := titleStyle.UnsetMarginBottom().Render("ws ui") + valueStyle.Render("  "+m.repo)
+	if !m.refreshed.IsZero() {
+		title += valueStyle.Render(fmt.Sprintf("  (updated %s)", m.refreshed.Format("15:04:05")))
+	}
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	switch {
+	case m.refreshed.IsZero():
+		b.WriteString(valueStyle.Render("Loading workspaces..."))
+		b.WriteString("\n")
+	case len(m.infos) == 0:
+		b.WriteString(valueStyle.Render("No workspaces. Create one with: ws new <name>"))
+		b.WriteString("\n")
+	default:
+		m.viewTable(&b)
+		m.viewDetails(&b)
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.confirm != "":
+		info, _ := m.selected()
+		question := fmt.Sprintf("Remove %s?", info.Name)
+		if m.confirm == "fold" {
+			question = fmt.Sprintf("Fold %s into %s?", info.Name, m.base)
+		}
+		b.WriteString(promptStyle.Render(question + " (y/n)"))
+		b.WriteString("\n")
+	case m.failed:
+		b.WriteString(errorStyle.Render("✗ " + m.message))
+		b.WriteString("\n")
+	case m.message != "":
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render("✓ " + m.message))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("↑/↓: select • enter: go • a: agent • d: diff • c: commits • l: log • f: fold • x: done • r: refresh • q: quit"))
+
+	return b.String()
+}
+
+// viewTable renders a row per workspace.
+func (m uiModel) viewTable(b *strings.Builder) {
+	header := []string{"WORKSPACE", "STATE", "AHEAD", "BEHIND", "DIRTY", "AGENT", "LAST COMMIT"}
+	rows := make([][]string, len(m.infos))
+	for i, info := range m.infos {
+		agent := "-"
+		if len(info.Agents) > 0 {
+			agent = info.Agents[0].Name
+			if len(info.Agents) > 1 {
+				agent += fmt.Sprintf(" +%d", len(info.Agents)-1)
+			}
+		}
+		last := "-"
+		if c := info.LastCommit; !c.IsZero() {
+			last = fmt.Sprintf("%s (%s)", firstLine(c.Subject, 40), formatAgo(c.Time))
+		}
+		rows[i] = []string{
+			info.Name,
+			string(info.State),
+			fmt.Sprint(info.Ahead),
+			fmt.Sprint(info.Behind),
+			fmt.Sprint(info.Dirty),
+			agent,
+			last,
+		}
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for j, cell := range row {
+			widths[j] = max(widths[j], lipgloss.Width(cell))
+		}
+	}
+	pad := func(cell string, j int) string {
+		if j == len(widths)-1 {
+			return cell
+		}
+		return cell + strings.Repeat(" ", widths[j]-lipgloss.Width(cell)+2)
+	}
+
+	b.WriteString("  ")
+	for j, cell := range header {
+		b.WriteString(uiHeaderStyle.Render(pad(cell, j)))
+	}
+	b.WriteString("\n")
+
+	end := min(m.offset+m.visibleRows(), len(rows))
+	for i := m.offset; i < end; i++ {
+		cursor, style := "  ", normalStyle.Padding(0)
+		if i == m.cursor {
+			cursor, style = "▸ ", style.Bold(true).Foreground(lipgloss.Color("229"))
+		}
+		b.WriteString(cursor)
+		for j, cell := range rows[i] {
+			cellStyle := style
+			if j == 1 {
+				cellStyle = uiStateStyles[m.infos[i].State]
+			}
+			b.WriteString(cellStyle.Render(pad(cell, j)))
+		}
+		b.WriteString("\n")
+	}
+	if len(rows) > end || m.offset > 0 {
+		b.WriteString(valueStyle.Render(fmt.Sprintf("  %d-%d of %d", m.offset+1, end, len(rows))))
+		b.WriteString("\n")
+	}
+}
+
+// viewDetails renders more about the selected workspace.
+func (m uiModel) viewDetails(b *strings.Builder) {
+	info, ok := m.selected()
+	if !ok {
+		return
+	}
+
+	lines := []string{
+		fmt.Sprintf("Path:     %s", shortenPath(info.Path)),
+		fmt.Sprintf("Branch:   %s (base %s)", info.Branch, info.Base),
+	}
+	if info.Operation != "" {
+		lines = append(lines, fmt.Sprintf("Git:      %s in progress", info.Operation))
+	}
+	if info.Error != "" {
+		lines = append(lines, fmt.Sprintf("Error:    %s", info.Error))
+	}
+	if len(info.Processes) > 0 {
+		lines = append(lines, fmt.Sprintf("Running:  %d process(es)", len(info.Processes)))
+	}
+	if info.Meta.Prompt != "" {
+		lines = append(lines, fmt.Sprintf("Prompt:   %s", firstLine(info.Meta.Prompt, 60)))
+	}
+	if n := len(info.Meta.Notes); n > 0 {
+		lines = append(lines, fmt.Sprintf("Note:     %s", firstLine(info.Meta.Notes[n-1].Text, 60)))
+	}
+	b.WriteString(uiDetailStyle.Render(strings.Join(lines, "\n")))
+	b.WriteString("\n")
+}
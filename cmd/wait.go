@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/workspace"
+)
+
+// waitConditions are the conditions 'ws wait' can wait for, and how to
+// tell they are met.
+var waitConditions = map[string]func(info workspace.Info) bool{
+	// No agent is running in the workspace
+	"agent-exited": func(info workspace.Info) bool {
+		return len(info.Agents) == 0
+	},
+	// Nothing is uncommitted
+	"clean": func(info workspace.Info) bool {
+		return info.Error == "" && info.Dirty == 0
+	},
+	// The branch has commits of its own and nothing is left uncommitted
+	"committed": func(info workspace.Info) bool {
+		return info.Error == "" && info.Dirty == 0 && info.Ahead > 0
+	},
+	// No conflicts are waiting to be resolved
+	"conflict-free": func(info workspace.Info) bool {
+		return info.Error == "" && info.State != workspace.StateConflicted
+	},
+}
+
+// WaitCmd handles the 'ws wait' command.
+func WaitCmd(args []string) int {
+	fs := flag.NewFlagSet("wait", flag.ExitOnError)
+	until := fs.String("until", "", "Condition to wait for: agent-exited, clean, committed or conflict-free")
+	timeout := fs.Duration("timeout", 0, "Give up after this long (default: wait forever)")
+	interval := fs.Duration("interval", 2*time.Second, "How often to check")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ws wait <name> --until CONDITION [--timeout DURATION]\n\n")
+		fmt.Fprintf(os.Stderr, "Block until a workspace reaches a condition.\n\n")
+		fmt.Fprintf(os.Stderr, "Conditions:\n")
+		fmt.Fprintf(os.Stderr, "  agent-exited    No agent is running in the workspace\n")
+		fmt.Fprintf(os.Stderr, "  clean           Nothing is uncommitted\n")
+		fmt.Fprintf(os.Stderr, "  committed       The branch is ahead of the base and nothing is uncommitted\n")
+		fmt.Fprintf(os.Stderr, "  conflict-free   No rebase or merge is stopped on conflicts\n\n")
+		fmt.Fprintf(os.Stderr, "Exit codes:\n")
+		fmt.Fprintf(os.Stderr, "  0  The condition was met\n")
+		fmt.Fprintf(os.Stderr, "  1  Error\n")
+		fmt.Fprintf(os.Stderr, "  2  Not in a git repository\n")
+		fmt.Fprintf(os.Stderr, "  3  Workspace not found\n")
+		fmt.Fprintf(os.Stderr, "  4  Timed out\n")
+		fmt.Fprintf(os.Stderr, "  5  The workspace was removed while waiting\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "ws: missing workspace name\n")
+		fmt.Fprintf(os.Stderr, "    Usage: ws wait <name> --until CONDITION\n")
+		return 1
+	}
+
+	// Accept flags after the name too, as in 'ws wait <name> --until clean'
+	name := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "ws: unexpected argument '%s'\n", fs.Arg(0))
+		return 1
+	}
+
+	met, ok := waitConditions[*until]
+	if !ok {
+		if *until == "" {
+			fmt.Fprintf(os.Stderr, "ws: missing --until condition\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "ws: unknown condition '%s'\n", *until)
+		}
+		fmt.Fprintf(os.Stderr, "    Expected one of agent-exited, clean, committed, conflict-free\n")
+		return 1
+	}
+	if *interval <= 0 {
+		fmt.Fprintf(os.Stderr, "ws: --interval must be positive\n")
+		return 1
+	}
+
+	mgr, err := workspace.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ws: %v\n", err)
+		if err.Error() == "not a git repository" {
+			fmt.Fprintf(os.Stderr, "    Run this command from within a git repository.\n")
+			return 2
+		}
+		return 1
+	}
+
+	ws, code := resolveWorkspace(mgr, name)
+	if code != 0 {
+		return code
+	}
+	name = ws.Name
+
+	if *until == "agent-exited" && !mgr.Config.Status.DetectProcesses {
+		fmt.Fprintf(os.Stderr, "ws: can't wait for agents with process detection disabled\n")
+		fmt.Fprintf(os.Stderr, "    Enable it with: ws config set detect_processes true\n")
+		return 1
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	base := mgr.Config.GetDefaultBase()
+
+	for {
+		ws, err := mgr.Get(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ws: workspace '%s' was removed\n", name)
+			return 5
+		}
+
+		classifier := mgr.NewStateClassifier()
+		if *until == "agent-exited" && !classifier.Detecting() {
+			fmt.Fprintf(os.Stderr, "ws: can't wait for agents without the process table\n")
+			return 1
+		}
+		if info := classifier.Inspect(ctx, *ws, base); ctx.Err() == nil && met(info) {
+			fmt.Printf("%s: %s\n", name, *until)
+			return 0
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(os.Stderr, "ws: timed out after %s waiting for %s (%s)\n", *timeout, name, *until)
+			return 4
+		case <-time.After(*interval):
+		}
+	}
+}
@@ -1,129 +1,302 @@
 package config
 
 import (
-	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/WillCMcC/ws/internal/git"
 )
 
+// RepoConfigFile is the per-repository config file, relative to the repo root.
+// It uses the same key=value format as the global config and is meant to be
+// committed so that everyone working on the repo shares the same settings.
+const RepoConfigFile = ".ws/config"
+
+// Source identifies which layer an effective config value came from.
+type Source int
+
+const (
+	SourceDefault Source = iota
+	SourceGlobal
+	SourceRepo
+	SourceEnv
+)
+
+// String returns the short name of the source.
+func (s Source) String() string {
+	switch s {
+	case SourceGlobal:
+		return "global"
+	case SourceRepo:
+		return "repo"
+	case SourceEnv:
+		return "env"
+	default:
+		return "default"
+	}
+}
+
+// Origin describes where an effective config value came from.
+// Location is the file path for file sources and the variable name for env.
+type Origin struct {
+	Source   Source
+	Location string
+}
+
+// String formats the origin for display, e.g. "repo:/src/app/.ws/config".
+func (o Origin) String() string {
+	if o.Location == "" {
+		return o.Source.String()
+	}
+	return o.Source.String() + ":" + o.Location
+}
+
 // Config holds the ws configuration.
 type Config struct {
 	Workspace WorkspaceConfig
 	Hooks     HooksConfig
 	Status    StatusConfig
 	Agent     AgentConfig
+
+	origins map[string]Origin
 }
 
 // WorkspaceConfig holds workspace-related settings.
 type WorkspaceConfig struct {
-	Directory   string
-	DefaultBase string
+	Directory    string
+	DefaultBase  string
+	CopyFiles    []string // Globs copied from the main worktree into new workspaces
+	LinkFiles    []string // Globs symlinked from the main worktree into new workspaces
+	SeedDirs     []string // Dependency directories cloned from the main worktree
+	SeedFallback string   // Fallback when reflinks are unsupported: copy, hardlink or none
 }
 
+// Seed fallback modes, used when the filesystem can't reflink.
+const (
+	SeedCopy     = "copy"
+	SeedHardlink = "hardlink"
+	SeedNone     = "none"
+)
+
 // AgentConfig holds agent-related settings.
 type AgentConfig struct {
-	Cmd string // Command to run for 'ws ez'
+	Cmd            string // Command to run for 'ws ez'
+	Launch         string // How to start it: auto, shell or native
+	PromptTemplate string // Command to run when there is a task prompt
 }
 
-// HooksConfig holds hook-related settings.
+// Agent launch modes.
+const (
+	LaunchAuto   = "auto"   // Shell when run through the shell integration, native otherwise
+	LaunchShell  = "shell"  // Hand the command to the shell integration to run
+	LaunchNative = "native" // Run the agent as a child of ws
+)
+
+// Hook failure policies.
+const (
+	PolicyWarn   = "warn"   // Print a warning and carry on
+	PolicyAbort  = "abort"  // Stop the operation that fired the hook
+	PolicyIgnore = "ignore" // Carry on silently
+)
+
+// Hook is a configured hook command and what to do when it fails.
+type Hook struct {
+	Command string
+	Policy  string
+}
+
+// HooksConfig holds the hook configured for each event.
 type HooksConfig struct {
-	PostCreate string
-	PreRemove  string
+	PreCreate        Hook
+	PostCreate       Hook
+	PreRemove        Hook
+	PostRemove       Hook
+	PreFold          Hook
+	PostFold         Hook
+	OnRebaseConflict Hook
+	PreAgent         Hook
+	PostAgent        Hook
+
+	Timeout time.Duration // Zero means no timeout
+}
+
+// Get returns the hook configured for an event such as "post-create".
+// Unknown events have no command.
+func (h HooksConfig) Get(event string) Hook {
+	switch event {
+	case "pre-create":
+		return h.PreCreate
+	case "post-create":
+		return h.PostCreate
+	case "pre-remove":
+		return h.PreRemove
+	case "post-remove":
+		return h.PostRemove
+	case "pre-fold":
+		return h.PreFold
+	case "post-fold":
+		return h.PostFold
+	case "on-rebase-conflict":
+		return h.OnRebaseConflict
+	case "pre-agent":
+		return h.PreAgent
+	case "post-agent":
+		return h.PostAgent
+	}
+	return Hook{Policy: PolicyWarn}
 }
 
 // StatusConfig holds status-related settings.
 type StatusConfig struct {
 	DetectProcesses bool
 	AgentProcesses  []string
+	AgentPatterns   []string // Regular expressions matched against command lines
+	AgentExePaths   []string
+	Timeout         time.Duration // How long to spend on each workspace; zero means no limit
 }
 
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
 	return &Config{
 		Workspace: WorkspaceConfig{
-			Directory:   ".worktrees/{repo}",
-			DefaultBase: "", // Empty means auto-detect (main or master)
-		},
-		Hooks: HooksConfig{
-			PostCreate: "",
-			PreRemove:  "",
+			Directory:    ".worktrees/{repo}",
+			DefaultBase:  "", // Empty means auto-detect (main or master)
+			SeedFallback: SeedCopy,
 		},
+		Hooks: defaultHooks(),
 		Status: StatusConfig{
 			DetectProcesses: true,
 			AgentProcesses:  []string{"claude", "opencode", "aider", "codex", "gemini"},
+			AgentPatterns: []string{
+				`^\S*node\S* \S*/(claude|codex|gemini|opencode)( |$)`,
+				`^\S*python\S* -m aider\b`,
+			},
+			Timeout: 10 * time.Second,
 		},
 		Agent: AgentConfig{
-			Cmd: "claude", // Default agent command
+			Cmd:            "claude", // Default agent command
+			Launch:         LaunchAuto,
+			PromptTemplate: "{cmd} {prompt}",
 		},
 	}
 }
 
+// defaultHooks returns hooks with no commands that warn on failure.
+func defaultHooks() HooksConfig {
+	var cfg Config
+	for _, h := range hookSchema {
+		h.hook(&cfg).Policy = PolicyWarn
+	}
+	cfg.Hooks.Timeout = 10 * time.Minute
+	return cfg.Hooks
+}
+
 // Load loads configuration from files and environment.
-func Load() *Config {
+// Values are layered in increasing priority: built-in defaults, the global
+// config file, the repository's config file (if repoRoot is non-empty), and
+// finally WS_* environment variables. Invalid values are reported as
+// warnings and otherwise ignored.
+func Load(repoRoot string) *Config {
 	cfg := DefaultConfig()
 
-	// Load from config file first
-	fileConfig := loadConfigFile()
-
-	// Apply config file values
-	if dir, ok := fileConfig["directory"]; ok && dir != "" {
-		cfg.Workspace.Directory = dir
+	// Global config file, then the repo file on top of it
+	globalPath := GlobalPath()
+	cfg.applyFile(ReadFile(globalPath), Origin{Source: SourceGlobal, Location: globalPath})
+	if repoRoot != "" {
+		repoPath := RepoPath(repoRoot)
+		cfg.applyFile(ReadFile(repoPath), Origin{Source: SourceRepo, Location: repoPath})
 	}
-	if base, ok := fileConfig["default_base"]; ok && base != "" {
-		cfg.Workspace.DefaultBase = base
+
+	// Override with environment variables (highest priority)
+	for _, key := range Keys {
+		if key.Env == "" {
+			continue
+		}
+		if value := os.Getenv(key.Env); value != "" {
+			cfg.apply(key, value, Origin{Source: SourceEnv, Location: key.Env})
+		}
 	}
-	if agentCmd, ok := fileConfig["agent_cmd"]; ok && agentCmd != "" {
-		cfg.Agent.Cmd = agentCmd
+	if HooksDisabled() {
+		for _, h := range hookSchema {
+			h.hook(cfg).Command = ""
+		}
 	}
 
-	// Override with environment variables (highest priority)
-	if dir := os.Getenv("WS_DIRECTORY"); dir != "" {
-		cfg.Workspace.Directory = dir
+	return cfg
+}
+
+// HooksDisabled reports whether WS_NO_HOOKS=1 turns off every hook, both
+// configured commands and the repository's hook scripts.
+func HooksDisabled() bool {
+	return os.Getenv("WS_NO_HOOKS") == "1"
+}
+
+// applyFile applies values read from a config file layer.
+// Empty values are treated as unset.
+func (c *Config) applyFile(values map[string]string, origin Origin) {
+	for _, key := range Keys {
+		if value, ok := values[key.Name]; ok && value != "" {
+			c.apply(key, value, origin)
+		}
 	}
-	if base := os.Getenv("WS_DEFAULT_BASE"); base != "" {
-		cfg.Workspace.DefaultBase = base
+}
+
+// apply sets a single key, recording its origin or warning if it is invalid.
+func (c *Config) apply(key Key, value string, origin Origin) {
+	if err := key.Set(c, value); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring invalid config value (%s): %v\n", origin, err)
+		return
 	}
-	if os.Getenv("WS_NO_HOOKS") == "1" {
-		cfg.Hooks.PostCreate = ""
-		cfg.Hooks.PreRemove = ""
+	if c.origins == nil {
+		c.origins = make(map[string]Origin)
 	}
-	if agentCmd := os.Getenv("WS_AGENT_CMD"); agentCmd != "" {
-		cfg.Agent.Cmd = agentCmd
+	c.origins[key.Name] = origin
+}
+
+// Origin returns where the effective value of key came from.
+func (c *Config) Origin(key string) Origin {
+	if origin, ok := c.origins[key]; ok {
+		return origin
 	}
+	return Origin{Source: SourceDefault}
+}
 
-	return cfg
+// Value returns the effective value of key, formatted as in a config file.
+func (c *Config) Value(key string) string {
+	if k, ok := Lookup(key); ok {
+		return k.Get(c)
+	}
+	return ""
 }
 
-// loadConfigFile reads the config file from ~/.config/ws/config
-func loadConfigFile() map[string]string {
+// GlobalPath returns the path of the global config file.
+func GlobalPath() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return nil
+		return ""
 	}
+	return filepath.Join(home, ".config", "ws", "config")
+}
 
-	path := filepath.Join(home, ".config", "ws", "config")
-	file, err := os.Open(path)
-	if err != nil {
+// RepoPath returns the path of the per-repository config file.
+func RepoPath(repoRoot string) string {
+	return filepath.Join(repoRoot, RepoConfigFile)
+}
+
+// ReadFile reads the values of a key=value config file. A missing or
+// unreadable file yields no values.
+func ReadFile(path string) map[string]string {
+	if path == "" {
 		return nil
 	}
-	defer file.Close()
-
-	config := make(map[string]string)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			config[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-		}
+	f, err := OpenFile(path)
+	if err != nil {
+		return nil
 	}
-	return config
+	return f.Values()
 }
 
 // GetWorkspaceDir returns the resolved workspace directory path.
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/WillCMcC/ws/internal/fsutil"
+)
+
+// File is a key=value config file kept as its original lines, so that
+// edits preserve comments, blank lines and key order and only touch the
+// line being changed.
+type File struct {
+	Path  string
+	lines []string
+}
+
+// OpenFile reads the config file at path. A missing file yields an empty
+// File that will be created on Save.
+func OpenFile(path string) (*File, error) {
+	f := &File{Path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, err
+	}
+	f.lines = splitLines(string(data))
+	return f, nil
+}
+
+// Values returns the key/value pairs in the file. If a key appears more
+// than once, the last occurrence wins.
+func (f *File) Values() map[string]string {
+	values := make(map[string]string)
+	for _, line := range f.lines {
+		if key, value, ok := parseLine(line); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// Get returns the value of key and whether it is present.
+func (f *File) Get(key string) (string, bool) {
+	value, ok := f.Values()[key]
+	return value, ok
+}
+
+// Set updates key in place, keeping the line's existing spacing around
+// '='. A key not yet in the file is appended at the end. Values can't
+// span lines, since each line of the file holds one setting.
+func (f *File) Set(key, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("%s can't contain a line break", key)
+	}
+	if i := f.lastIndex(key); i >= 0 {
+		line := f.lines[i]
+		eq := strings.Index(line, "=")
+		prefix := line[:eq+1]
+		rest := line[eq+1:]
+		prefix += rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+		f.lines[i] = prefix + value
+		return nil
+	}
+	f.lines = append(f.lines, key+"="+value)
+	return nil
+}
+
+// Unset removes every line that sets key. It reports whether any were found.
+func (f *File) Unset(key string) bool {
+	kept := f.lines[:0]
+	removed := false
+	for _, line := range f.lines {
+		if k, _, ok := parseLine(line); ok && k == key {
+			removed = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	f.lines = kept
+	return removed
+}
+
+// Validate checks every line against the schema and returns one error per
+// problem, prefixed with its line number.
+func (f *File) Validate() []error {
+	var errs []error
+	for i, line := range f.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, ok := parseLine(line)
+		if !ok {
+			errs = append(errs, fmt.Errorf("line %d: expected key=value", i+1))
+			continue
+		}
+		schemaKey, known := Lookup(key)
+		if !known {
+			errs = append(errs, fmt.Errorf("line %d: unknown key '%s'", i+1, key))
+			continue
+		}
+		// Empty values mean unset
+		if value == "" {
+			continue
+		}
+		if err := schemaKey.Validate(value); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %v", i+1, err))
+		}
+	}
+	return errs
+}
+
+// Save atomically writes the file back to disk.
+func (f *File) Save() error {
+	var b strings.Builder
+	for _, line := range f.lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return fsutil.WriteFileAtomic(f.Path, []byte(b.String()), 0644)
+}
+
+// lastIndex returns the index of the last line setting key, or -1.
+func (f *File) lastIndex(key string) int {
+	for i := len(f.lines) - 1; i >= 0; i-- {
+		if k, _, ok := parseLine(f.lines[i]); ok && k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseLine parses a key=value line. Comments and blank lines are not
+// key/value lines.
+func parseLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
+
+// splitLines splits file content into lines, dropping the final newline.
+func splitLines(content string) []string {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
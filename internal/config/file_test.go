@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestFileSet(t *testing.T) {
+	f := &File{lines: []string{"# comment", "agent_cmd = claude", ""}}
+
+	if err := f.Set("agent_cmd", "aider"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("default_base", "main"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"# comment", "agent_cmd = aider", "", "default_base=main"}
+	if len(f.lines) != len(want) {
+		t.Fatalf("lines = %q, want %q", f.lines, want)
+	}
+	for i := range want {
+		if f.lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, f.lines[i], want[i])
+		}
+	}
+
+	for _, value := range []string{"a\nb", "a\r\nb", "a\r"} {
+		if err := f.Set("agent_cmd", value); err == nil {
+			t.Errorf("Set(agent_cmd, %q) succeeded, want an error", value)
+		}
+	}
+	if value, _ := f.Get("agent_cmd"); value != "aider" {
+		t.Errorf("agent_cmd = %q after rejected sets, want aider", value)
+	}
+}
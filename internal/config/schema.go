@@ -0,0 +1,431 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the value type of a config key.
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeBool
+	TypeList
+	TypeDuration
+)
+
+// String returns the name of the type as shown to users.
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeList:
+		return "list"
+	case TypeDuration:
+		return "duration"
+	default:
+		return "string"
+	}
+}
+
+// Key describes a single configuration key: how it is named in config
+// files and the environment, what it means, and where it lives in Config.
+type Key struct {
+	Name        string
+	Type        Type
+	Env         string // Environment variable that overrides the key, if any
+	Description string
+	Example     string
+
+	// validate checks an already-parsed value. It may be nil.
+	validate func(value any) error
+	// field returns a pointer to the key's field in c: *string, *bool,
+	// *[]string or *time.Duration.
+	field func(c *Config) any
+}
+
+// Keys is the schema of every supported configuration key, in display order.
+var Keys = buildKeys()
+
+func buildKeys() []Key {
+	keys := append([]Key{}, workspaceKeys...)
+	keys = append(keys, hookKeys()...)
+	return append(keys, statusKeys...)
+}
+
+var workspaceKeys = []Key{
+	{
+		Name:        "agent_cmd",
+		Type:        TypeString,
+		Env:         "WS_AGENT_CMD",
+		Description: "Command to run when using 'ws ez'. This starts your AI coding agent in the new workspace.",
+		Example:     "claude --dangerously-skip-permissions",
+		field:       func(c *Config) any { return &c.Agent.Cmd },
+	},
+	{
+		Name:        "agent_launch",
+		Type:        TypeString,
+		Env:         "WS_AGENT_LAUNCH",
+		Description: "How 'ws ez' and 'ws agent' start the agent: shell (run by the shell integration), native (run by ws itself) or auto (shell when the integration is installed).",
+		Example:     "native",
+		validate:    oneOf(LaunchAuto, LaunchShell, LaunchNative),
+		field:       func(c *Config) any { return &c.Agent.Launch },
+	},
+	{
+		Name:        "agent_prompt_template",
+		Type:        TypeString,
+		Env:         "WS_AGENT_PROMPT_TEMPLATE",
+		Description: "Command that starts the agent on a task given with --prompt. {prompt} is replaced by the shell-quoted prompt and {cmd} by agent_cmd.",
+		Example:     "aider --message {prompt}",
+		validate:    contains("{prompt}"),
+		field:       func(c *Config) any { return &c.Agent.PromptTemplate },
+	},
+	{
+		Name:        "default_base",
+		Type:        TypeString,
+		Env:         "WS_DEFAULT_BASE",
+		Description: "Default branch to use as base when creating new workspaces. Leave empty to auto-detect (main/master).",
+		Example:     "develop",
+		validate:    noWhitespace,
+		field:       func(c *Config) any { return &c.Workspace.DefaultBase },
+	},
+	{
+		Name:        "directory",
+		Type:        TypeString,
+		Env:         "WS_DIRECTORY",
+		Description: "Pattern for workspace directory location. Use {repo} as placeholder for repository name.",
+		Example:     "../{repo}-ws",
+		field:       func(c *Config) any { return &c.Workspace.Directory },
+	},
+	{
+		Name:        "copy_files",
+		Type:        TypeList,
+		Env:         "WS_COPY_FILES",
+		Description: "Comma-separated globs, relative to the repository root, of untracked files copied into new workspaces.",
+		Example:     ".env, config/local.yml",
+		validate:    relativePaths,
+		field:       func(c *Config) any { return &c.Workspace.CopyFiles },
+	},
+	{
+		Name:        "link_files",
+		Type:        TypeList,
+		Env:         "WS_LINK_FILES",
+		Description: "Comma-separated globs, relative to the repository root, of untracked files symlinked into new workspaces.",
+		Example:     ".tool-versions, .envrc",
+		validate:    relativePaths,
+		field:       func(c *Config) any { return &c.Workspace.LinkFiles },
+	},
+	{
+		Name:        "seed_dirs",
+		Type:        TypeList,
+		Env:         "WS_SEED_DIRS",
+		Description: "Comma-separated dependency directories cloned from the main worktree into new workspaces using copy-on-write reflinks.",
+		Example:     "node_modules, .venv, target",
+		validate:    relativePaths,
+		field:       func(c *Config) any { return &c.Workspace.SeedDirs },
+	},
+	{
+		Name:        "seed_fallback",
+		Type:        TypeString,
+		Env:         "WS_SEED_FALLBACK",
+		Description: "How to seed directories when the filesystem can't reflink: copy, hardlink (shares files with the main worktree) or none.",
+		Example:     "hardlink",
+		validate:    oneOf(SeedCopy, SeedHardlink, SeedNone),
+		field:       func(c *Config) any { return &c.Workspace.SeedFallback },
+	},
+}
+
+// hookSchema describes the hook configured for each event, in lifecycle order.
+var hookSchema = []struct {
+	event       string
+	description string
+	example     string
+	hook        func(c *Config) *Hook
+}{
+	{"pre-create", "Shell command run in the main repository before a workspace is created.", "git fetch origin", func(c *Config) *Hook { return &c.Hooks.PreCreate }},
+	{"post-create", "Shell command run in a new workspace after it is created.", "npm install", func(c *Config) *Hook { return &c.Hooks.PostCreate }},
+	{"pre-remove", "Shell command run in a workspace before it is removed.", "docker compose down", func(c *Config) *Hook { return &c.Hooks.PreRemove }},
+	{"post-remove", "Shell command run in the main repository after a workspace is removed.", "echo \"removed {name}\" >> ~/ws.log", func(c *Config) *Hook { return &c.Hooks.PostRemove }},
+	{"pre-fold", "Shell command run in a workspace before 'ws fold' rebases it.", "make test", func(c *Config) *Hook { return &c.Hooks.PreFold }},
+	{"post-fold", "Shell command run in a workspace after 'ws fold' has merged it.", "echo \"folded {name}\" >> ~/ws.log", func(c *Config) *Hook { return &c.Hooks.PostFold }},
+	{"on-rebase-conflict", "Shell command run in a workspace when the rebase in 'ws fold' stops on conflicts.", "notify-send \"ws: {name} has conflicts\"", func(c *Config) *Hook { return &c.Hooks.OnRebaseConflict }},
+	{"pre-agent", "Shell command run in a workspace before the agent is started by 'ws ez' or 'ws auto-rebase'.", "npm install", func(c *Config) *Hook { return &c.Hooks.PreAgent }},
+	{"post-agent", "Shell command run in a workspace after the agent started by 'ws ez' or 'ws auto-rebase' exits.", "pkill -f 'vite --port'", func(c *Config) *Hook { return &c.Hooks.PostAgent }},
+}
+
+// hookKeys returns a command key and a failure policy key for every hook
+// event, followed by the shared hook timeout.
+func hookKeys() []Key {
+	var keys []Key
+	for _, h := range hookSchema {
+		name := HookKey(h.event)
+		env := "WS_" + strings.ToUpper(name)
+		keys = append(keys,
+			Key{
+				Name:        name,
+				Type:        TypeString,
+				Env:         env,
+				Description: h.description + " {name} and {path} are substituted.",
+				Example:     h.example,
+				field:       func(c *Config) any { return &h.hook(c).Command },
+			},
+			Key{
+				Name:        name + "_policy",
+				Type:        TypeString,
+				Env:         env + "_POLICY",
+				Description: fmt.Sprintf("What to do when the %s hook fails: warn, abort or ignore.", h.event),
+				Example:     PolicyAbort,
+				validate:    oneOf(PolicyWarn, PolicyAbort, PolicyIgnore),
+				field:       func(c *Config) any { return &h.hook(c).Policy },
+			},
+		)
+	}
+	return append(keys, Key{
+		Name:        "hook_timeout",
+		Type:        TypeDuration,
+		Env:         "WS_HOOK_TIMEOUT",
+		Description: "How long a hook may run before it is killed. 0 disables the timeout.",
+		Example:     "2m",
+		field:       func(c *Config) any { return &c.Hooks.Timeout },
+	})
+}
+
+// HookKey returns the config key holding the command for a hook event,
+// e.g. "post_create" for "post-create". Its policy key adds "_policy".
+func HookKey(event string) string {
+	return strings.ReplaceAll(event, "-", "_")
+}
+
+var statusKeys = []Key{
+	{
+		Name:        "detect_processes",
+		Type:        TypeBool,
+		Env:         "WS_DETECT_PROCESSES",
+		Description: "Detect agent processes running in each workspace for 'ws list' and 'ws status'.",
+		Example:     "false",
+		field:       func(c *Config) any { return &c.Status.DetectProcesses },
+	},
+	{
+		Name:        "agent_processes",
+		Type:        TypeList,
+		Env:         "WS_AGENT_PROCESSES",
+		Description: "Comma-separated process names treated as agents by process detection.",
+		Example:     "claude, aider, codex",
+		validate:    noWhitespace,
+		field:       func(c *Config) any { return &c.Status.AgentProcesses },
+	},
+	{
+		Name:        "agent_cmdline_patterns",
+		Type:        TypeList,
+		Env:         "WS_AGENT_CMDLINE_PATTERNS",
+		Description: "Comma-separated regular expressions matched against full command lines, for agents running under an interpreter such as node or python.",
+		Example:     "-m aider\\b, claude-code/cli\\.js",
+		validate:    regexps,
+		field:       func(c *Config) any { return &c.Status.AgentPatterns },
+	},
+	{
+		Name:        "agent_exe_paths",
+		Type:        TypeList,
+		Env:         "WS_AGENT_EXE_PATHS",
+		Description: "Comma-separated executables, or directories of executables, treated as agents by process detection.",
+		Example:     "~/.local/bin/claude, /opt/agents",
+		field:       func(c *Config) any { return &c.Status.AgentExePaths },
+	},
+	{
+		Name:        "status_timeout",
+		Type:        TypeDuration,
+		Env:         "WS_STATUS_TIMEOUT",
+		Description: "How long 'ws list' and 'ws status' may spend reading each workspace's git state before reporting it as unknown. 0 disables the timeout.",
+		Example:     "30s",
+		field:       func(c *Config) any { return &c.Status.Timeout },
+	},
+}
+
+// Lookup returns the schema entry for a key name.
+func Lookup(name string) (Key, bool) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// KeyNames returns the names of all supported keys, in display order.
+func KeyNames() []string {
+	names := make([]string, 0, len(Keys))
+	for _, key := range Keys {
+		names = append(names, key.Name)
+	}
+	return names
+}
+
+// Get returns the key's value in c, formatted as it would be written to a
+// config file.
+func (k Key) Get(c *Config) string {
+	switch v := k.field(c).(type) {
+	case *string:
+		return *v
+	case *bool:
+		return strconv.FormatBool(*v)
+	case *[]string:
+		return strings.Join(*v, ", ")
+	case *time.Duration:
+		return v.String()
+	}
+	return ""
+}
+
+// Default returns the key's built-in default value.
+func (k Key) Default() string {
+	return k.Get(DefaultConfig())
+}
+
+// Validate reports whether raw is an acceptable value for the key.
+func (k Key) Validate(raw string) error {
+	_, err := k.parse(raw)
+	return err
+}
+
+// Set parses raw and stores it in c.
+func (k Key) Set(c *Config, raw string) error {
+	value, err := k.parse(raw)
+	if err != nil {
+		return err
+	}
+	switch field := k.field(c).(type) {
+	case *string:
+		*field = value.(string)
+	case *bool:
+		*field = value.(bool)
+	case *[]string:
+		*field = value.([]string)
+	case *time.Duration:
+		*field = value.(time.Duration)
+	}
+	return nil
+}
+
+// parse converts raw into the key's Go type and runs its validation.
+func (k Key) parse(raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+
+	var value any
+	switch k.Type {
+	case TypeBool:
+		b, err := parseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k.Name, err)
+		}
+		value = b
+	case TypeList:
+		value = parseList(raw)
+	case TypeDuration:
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%s: expected a duration such as 30s or 5m, got %q", k.Name, raw)
+		}
+		value = d
+	default:
+		value = raw
+	}
+
+	if k.validate != nil {
+		if err := k.validate(value); err != nil {
+			return nil, fmt.Errorf("%s: %w", k.Name, err)
+		}
+	}
+	return value, nil
+}
+
+// parseBool accepts the usual spellings of true and false.
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(raw) {
+	case "1", "t", "true", "yes", "y", "on":
+		return true, nil
+	case "0", "f", "false", "no", "n", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected true or false, got %q", raw)
+}
+
+// parseList splits a comma-separated list, dropping empty items.
+func parseList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// oneOf returns a validator accepting only the given strings.
+func oneOf(allowed ...string) func(value any) error {
+	return func(value any) error {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s, got %q", strings.Join(allowed, ", "), value)
+	}
+}
+
+// contains returns a validator requiring a string to include substr.
+func contains(substr string) func(value any) error {
+	return func(value any) error {
+		if !strings.Contains(value.(string), substr) {
+			return fmt.Errorf("must contain %s", substr)
+		}
+		return nil
+	}
+}
+
+// relativePaths rejects list items that are absolute or escape the
+// repository with "..".
+func relativePaths(value any) error {
+	for _, item := range value.([]string) {
+		if filepath.IsAbs(item) {
+			return fmt.Errorf("%q must be relative to the repository root", item)
+		}
+		for _, part := range strings.Split(filepath.ToSlash(item), "/") {
+			if part == ".." {
+				return fmt.Errorf("%q must not contain '..'", item)
+			}
+		}
+	}
+	return nil
+}
+
+// regexps rejects list items that aren't valid regular expressions.
+func regexps(value any) error {
+	for _, item := range value.([]string) {
+		if _, err := regexp.Compile(item); err != nil {
+			return fmt.Errorf("%q is not a valid regular expression: %v", item, err)
+		}
+	}
+	return nil
+}
+
+// noWhitespace rejects strings or list items that contain whitespace.
+func noWhitespace(value any) error {
+	var items []string
+	switch v := value.(type) {
+	case string:
+		items = []string{v}
+	case []string:
+		items = v
+	}
+	for _, item := range items {
+		if strings.ContainsAny(item, " \t") {
+			return fmt.Errorf("%q must not contain whitespace", item)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package fsutil
+
+// Lock is a no-op where flock isn't available.
+func Lock(path string) (func(), error) {
+	return func() {}, nil
+}
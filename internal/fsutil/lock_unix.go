@@ -0,0 +1,30 @@
+//go:build unix
+
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Lock takes an exclusive lock on the file at path, creating it if
+// needed, and waits until it gets it. Call the returned function to
+// release it. The lock is advisory: it only keeps out others that take it.
+func Lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
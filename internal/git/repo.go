@@ -87,3 +87,38 @@ func GetCurrentBranch() (string, error) {
 	}
 	return strings.TrimSpace(string(output)), nil
 }
+
+// GetBranch returns the branch checked out in the worktree at path.
+func GetBranch(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--abbrev-ref", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetHead returns the commit checked out in the worktree at path.
+func GetHead(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetCommonDir returns the absolute path of the git directory shared by
+// every worktree of the repository at path.
+func GetCommonDir(path string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--git-common-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return dir, nil
+}
@@ -2,19 +2,24 @@ package git
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Worktree represents a git worktree.
 type Worktree struct {
-	Path   string
-	Head   string
-	Branch string
-	Bare   bool
+	Path       string
+	Head       string
+	Branch     string
+	Bare       bool
+	Locked     bool
+	LockReason string
 }
 
 // CreateWorktree creates a new worktree with a new branch.
@@ -88,6 +93,9 @@ func parseWorktreePorcelain(output string) ([]Worktree, error) {
 			current.Branch = strings.TrimPrefix(branch, "refs/heads/")
 		} else if line == "bare" && current != nil {
 			current.Bare = true
+		} else if (line == "locked" || strings.HasPrefix(line, "locked ")) && current != nil {
+			current.Locked = true
+			current.LockReason = strings.TrimPrefix(strings.TrimPrefix(line, "locked"), " ")
 		}
 	}
 
@@ -107,9 +115,24 @@ func PruneWorktrees() error {
 	return cmd.Run()
 }
 
+// commandContext returns a git command that is killed when ctx is done.
+// Output is abandoned shortly after, even if something git started, such
+// as an fsmonitor hook, still holds it open.
+func commandContext(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
 // GetWorktreeStatus returns the git status for a worktree.
 func GetWorktreeStatus(path string) (string, error) {
-	cmd := exec.Command("git", "-C", path, "status", "--porcelain")
+	return GetWorktreeStatusContext(context.Background(), path)
+}
+
+// GetWorktreeStatusContext is GetWorktreeStatus with a context that can
+// cancel the underlying git command.
+func GetWorktreeStatusContext(ctx context.Context, path string) (string, error) {
+	cmd := commandContext(ctx, "-C", path, "status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -126,30 +149,87 @@ func HasUncommittedChanges(path string) (bool, string, error) {
 	return status != "", status, nil
 }
 
-// GetCommitsAhead returns how many commits the branch is ahead of base.
-func GetCommitsAhead(path, base string) (int, error) {
-	cmd := exec.Command("git", "-C", path, "rev-list", "--count", fmt.Sprintf("%s..HEAD", base))
+// GetOperation returns the operation left in progress in the worktree at
+// path: "rebase", "merge", "cherry-pick" or "revert", or "" if none.
+func GetOperation(ctx context.Context, path string) string {
+	markers := []struct{ file, op string }{
+		{"rebase-merge", "rebase"},
+		{"rebase-apply", "rebase"},
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+		{"REVERT_HEAD", "revert"},
+	}
+
+	// Each marker lives in the worktree's own git dir; resolve them all at once
+	args := []string{"-C", path, "rev-parse"}
+	for _, m := range markers {
+		args = append(args, "--git-path", m.file)
+	}
+	output, err := commandContext(ctx, args...).Output()
+	if err != nil {
+		return ""
+	}
+
+	paths := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for i, marker := range paths {
+		if i >= len(markers) {
+			break
+		}
+		if !filepath.IsAbs(marker) {
+			marker = filepath.Join(path, marker)
+		}
+		if _, err := os.Stat(marker); err == nil {
+			return markers[i].op
+		}
+	}
+	return ""
+}
+
+// HasUnmergedPaths reports whether git status porcelain output lists any
+// paths with unresolved conflicts.
+func HasUnmergedPaths(status string) bool {
+	for _, line := range strings.Split(status, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		switch line[:2] {
+		case "DD", "AU", "UD", "UA", "DU", "AA", "UU":
+			return true
+		}
+	}
+	return false
+}
+
+// GetAheadBehind returns how many commits the branch is ahead of and
+// behind base.
+func GetAheadBehind(ctx context.Context, path, base string) (int, int, error) {
+	cmd := commandContext(ctx, "-C", path, "rev-list", "--left-right", "--count", fmt.Sprintf("%s...HEAD", base))
 	output, err := cmd.Output()
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-	var count int
-	_, err = fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &count)
-	return count, err
+	var behind, ahead int
+	_, err = fmt.Sscanf(strings.TrimSpace(string(output)), "%d %d", &behind, &ahead)
+	return ahead, behind, err
 }
 
-// GetLastCommit returns the last commit info for a worktree.
-func GetLastCommit(path string) (string, string, error) {
-	cmd := exec.Command("git", "-C", path, "log", "-1", "--format=%s|%ar")
+// GetHeadCommit returns the hash, subject and commit time of the commit
+// checked out in the worktree at path.
+func GetHeadCommit(ctx context.Context, path string) (string, string, time.Time, error) {
+	cmd := commandContext(ctx, "-C", path, "log", "-1", "--format=%H%x00%ct%x00%s")
 	output, err := cmd.Output()
 	if err != nil {
-		return "", "", err
+		return "", "", time.Time{}, err
+	}
+	parts := strings.SplitN(strings.TrimSpace(string(output)), "\x00", 3)
+	if len(parts) != 3 {
+		return "", "", time.Time{}, fmt.Errorf("unexpected log format")
 	}
-	parts := strings.SplitN(strings.TrimSpace(string(output)), "|", 2)
-	if len(parts) != 2 {
-		return "", "", fmt.Errorf("unexpected log format")
+	seconds, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("unexpected log format")
 	}
-	return parts[0], parts[1], nil
+	return parts[0], parts[2], time.Unix(seconds, 0), nil
 }
 
 // WorktreeExists checks if a worktree exists at the given path.
@@ -0,0 +1,14 @@
+package process
+
+// ProcessInspector reads the process table.
+type ProcessInspector interface {
+	// Processes returns every process visible to the current user.
+	// Fields that can't be read (another user's cwd, say) are left empty.
+	Processes() ([]Process, error)
+}
+
+// NewInspector returns the best inspector for the current platform: a
+// native /proc reader on Linux, and ps plus lsof elsewhere.
+func NewInspector() ProcessInspector {
+	return defaultInspector()
+}
@@ -0,0 +1,164 @@
+//go:build linux
+
+package process
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procInspector reads process information straight from /proc, needing
+// neither ps nor lsof.
+type procInspector struct {
+	root string
+}
+
+func defaultInspector() ProcessInspector {
+	if _, err := os.Stat("/proc/self/stat"); err == nil {
+		return procInspector{root: "/proc"}
+	}
+	// /proc isn't mounted (some sandboxes); fall back to the tools
+	return psInspector{}
+}
+
+// Processes returns every process in /proc.
+func (p procInspector) Processes() ([]Process, error) {
+	entries, err := os.ReadDir(p.root)
+	if err != nil {
+		return nil, err
+	}
+
+	boot := p.bootTime()
+
+	var procs []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// The process may have exited since the directory was listed
+		if proc, ok := p.read(pid, boot); ok {
+			procs = append(procs, proc)
+		}
+	}
+	return procs, nil
+}
+
+// read collects a single process's details. Start times in /proc are
+// relative to boot.
+func (p procInspector) read(pid int, boot time.Time) (Process, bool) {
+	dir := filepath.Join(p.root, strconv.Itoa(pid))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return Process{}, false
+	}
+
+	// The command name is parenthesised and may itself contain spaces or
+	// parentheses, so fields are counted from the last ')'
+	end := bytes.LastIndexByte(stat, ')')
+	start := bytes.IndexByte(stat, '(')
+	if start < 0 || end < start {
+		return Process{}, false
+	}
+	// fields[0] is field 3 of stat(5), the process state
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 22 {
+		return Process{}, false
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	started, _ := strconv.ParseInt(fields[19], 10, 64)
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+
+	proc := Process{
+		PID:     pid,
+		PPID:    ppid,
+		Name:    string(stat[start+1 : end]),
+		RSS:     rss * int64(os.Getpagesize()),
+		CPUTime: ticks(utime + stime),
+		Started: boot.Add(ticks(started)),
+	}
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		cmdline = bytes.TrimRight(cmdline, "\x00")
+		if len(cmdline) > 0 {
+			proc.Args = strings.Split(string(cmdline), "\x00")
+		}
+	}
+
+	if fields[0] == "S" {
+		proc.ReadingTTY = p.readingTTY(dir)
+	}
+
+	// Unreadable for other users' processes; that's fine
+	proc.Exe, _ = os.Readlink(filepath.Join(dir, "exe"))
+	proc.Cwd, _ = os.Readlink(filepath.Join(dir, "cwd"))
+
+	return proc, true
+}
+
+// zombie reports whether pid has exited but not yet been reaped by its
+// parent, which /proc shows as state Z.
+func zombie(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return false
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+// readingTTY reports whether a sleeping process is blocked in a read of
+// the terminal on its standard input.
+func (p procInspector) readingTTY(dir string) bool {
+	wchan, err := os.ReadFile(filepath.Join(dir, "wchan"))
+	if err != nil {
+		return false
+	}
+	// n_tty_read sleeps in wait_woken on current kernels
+	switch string(wchan) {
+	case "n_tty_read", "wait_woken":
+	default:
+		return false
+	}
+	stdin, err := os.Readlink(filepath.Join(dir, "fd", "0"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(stdin, "/dev/pts/") || strings.HasPrefix(stdin, "/dev/tty")
+}
+
+// bootTime returns when the system booted, from the btime line of
+// /proc/stat.
+func (p procInspector) bootTime() time.Time {
+	data, err := os.ReadFile(filepath.Join(p.root, "stat"))
+	if err != nil {
+		return time.Time{}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			secs, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			return time.Unix(secs, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// clockTicks is USER_HZ, the unit of /proc times. It is 100 on every
+// architecture Linux supports today.
+const clockTicks = 100
+
+// ticks converts a count of clock ticks to a duration.
+func ticks(n int64) time.Duration {
+	return time.Duration(n) * time.Second / clockTicks
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// writeProc writes a fake /proc/<pid> under root with the given command
+// name and arguments.
+func writeProc(t *testing.T, root string, pid, ppid int, comm string, args ...string) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Fields 3 to 24 of stat(5): state, ppid, ..., utime (14), stime (15),
+	// ..., starttime (22), vsize, rss (24)
+	stat := fmt.Sprintf("%d (%s) R %d 1 1 0 -1 0 0 0 0 0 150 50 0 0 20 0 1 0 1000 1024 10 0\n", pid, comm, ppid)
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cmdline []byte
+	for _, arg := range args {
+		cmdline = append(append(cmdline, arg...), 0)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), cmdline, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcInspector(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "stat"), []byte("cpu  1 2 3\nbtime 1792195200\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeProc(t, root, 10, 1, "node", "node", "/usr/lib/node_modules/@anthropic-ai/claude-code/cli.js")
+	writeProc(t, root, 11, 10, "tmux: server", "tmux")
+	writeProc(t, root, 12, 10, "a) (b", "weird")
+	writeProc(t, root, 13, 10, "kworker/0:1")
+	// Not processes
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "14"), 0755); err != nil { // Exited mid-scan
+		t.Fatal(err)
+	}
+
+	table, err := SnapshotWith(procInspector{root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pid  int
+		name string
+		args []string
+	}{
+		{10, "node", []string{"node", "/usr/lib/node_modules/@anthropic-ai/claude-code/cli.js"}},
+		{11, "tmux: server", []string{"tmux"}},
+		{12, "a) (b", []string{"weird"}},
+		{13, "kworker/0:1", nil}, // Kernel threads have no command line
+	}
+	for _, tt := range tests {
+		p, ok := table.Get(tt.pid)
+		if !ok {
+			t.Errorf("process %d missing", tt.pid)
+			continue
+		}
+		if p.Name != tt.name || !reflect.DeepEqual(p.Args, tt.args) {
+			t.Errorf("process %d = %q %q, want %q %q", tt.pid, p.Name, p.Args, tt.name, tt.args)
+		}
+	}
+	if _, ok := table.Get(14); ok {
+		t.Errorf("process 14 without a stat file was listed")
+	}
+
+	p, _ := table.Get(12)
+	if p.PPID != 10 {
+		t.Errorf("PPID = %d, want 10", p.PPID)
+	}
+	if want := 2 * time.Second; p.CPUTime != want {
+		t.Errorf("CPUTime = %s, want %s", p.CPUTime, want)
+	}
+	if want := time.Unix(1792195200, 0).Add(10 * time.Second); !p.Started.Equal(want) {
+		t.Errorf("Started = %s, want %s", p.Started, want)
+	}
+	if want := int64(10 * os.Getpagesize()); p.RSS != want {
+		t.Errorf("RSS = %d, want %d", p.RSS, want)
+	}
+}
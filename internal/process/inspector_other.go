@@ -0,0 +1,13 @@
+//go:build !linux
+
+package process
+
+func defaultInspector() ProcessInspector {
+	return psInspector{}
+}
+
+// zombie reports whether pid has exited but not been reaped. Without /proc
+// it can't tell, and says no.
+func zombie(pid int) bool {
+	return false
+}
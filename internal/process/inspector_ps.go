@@ -0,0 +1,143 @@
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// psInspector reads the process table with ps and working directories
+// with lsof. It works on macOS and most Unix systems; each snapshot costs
+// four subprocesses regardless of how many processes there are.
+type psInspector struct{}
+
+// Processes returns every process along with its working directory.
+func (psInspector) Processes() ([]Process, error) {
+	// ucomm is the bare command name, without the path that comm has on
+	// macOS. It may contain spaces, so it comes last.
+	stats, err := psColumns("pid=,ppid=,rss=,etime=,time=,ucomm=")
+	if err != nil {
+		return nil, err
+	}
+	args, err := psColumns("pid=,args=")
+	if err != nil {
+		return nil, err
+	}
+	// comm is the executable's full path on macOS but only its name on
+	// other systems, where the executable is left unknown
+	exes, err := psColumns("pid=,comm=")
+	if err != nil {
+		return nil, err
+	}
+	cwds := lsofCwds()
+
+	now := time.Now()
+	procs := make([]Process, 0, len(stats))
+	for pid, row := range stats {
+		fields := strings.Fields(row)
+		if len(fields) < 5 {
+			continue
+		}
+		ppid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		rss, _ := strconv.ParseInt(fields[1], 10, 64)
+		elapsed, _ := parsePsTime(fields[2])
+		cpu, _ := parsePsTime(fields[3])
+
+		procs = append(procs, Process{
+			PID:     pid,
+			PPID:    ppid,
+			Name:    strings.Join(fields[4:], " "),
+			Args:    strings.Fields(args[pid]),
+			Exe:     absPath(exes[pid]),
+			Cwd:     cwds[pid],
+			RSS:     rss * 1024,
+			CPUTime: cpu,
+			Started: now.Add(-elapsed),
+		})
+	}
+	return procs, nil
+}
+
+// parsePsTime parses the [[dd-]hh:]mm:ss[.cc] durations ps prints for
+// etime and time.
+func parsePsTime(s string) (time.Duration, error) {
+	var days int
+	if d, rest, ok := strings.Cut(s, "-"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, err
+		}
+		days, s = n, rest
+	}
+
+	var total float64
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		total = total*60 + n
+	}
+	total += float64(days) * 24 * 60 * 60
+	return time.Duration(total * float64(time.Second)), nil
+}
+
+// psColumns runs ps over all processes with the given output format, whose
+// first column must be the pid, and returns the remaining columns by pid.
+func psColumns(format string) (map[int]string, error) {
+	cmd := exec.Command("ps", "-axww", "-o", format)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[int]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		pidField, rest, _ := strings.Cut(line, " ")
+		pid, err := strconv.Atoi(pidField)
+		if err != nil {
+			continue
+		}
+		rows[pid] = strings.TrimSpace(rest)
+	}
+	return rows, scanner.Err()
+}
+
+// absPath returns path if it is absolute, or "" otherwise.
+func absPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return ""
+}
+
+// lsofCwds returns the working directory of every process lsof can see.
+// A missing lsof yields no directories rather than an error.
+func lsofCwds() map[int]string {
+	cwds := make(map[int]string)
+
+	// lsof -d cwd -Fpn prints a "p<pid>" line followed by an "n<path>" line
+	cmd := exec.Command("lsof", "-w", "-d", "cwd", "-Fpn")
+	output, _ := cmd.Output()
+
+	pid := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "p"):
+			pid, _ = strconv.Atoi(line[1:])
+		case strings.HasPrefix(line, "n") && pid != 0:
+			cwds[pid] = line[1:]
+		}
+	}
+	return cwds
+}
@@ -0,0 +1,33 @@
+package process
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePsTime(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"00:05", 5 * time.Second},
+		{"12:34", 12*time.Minute + 34*time.Second},
+		{"01:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"2-03:04:05", 51*time.Hour + 4*time.Minute + 5*time.Second},
+		{"0:01.50", 1500 * time.Millisecond}, // time on macOS has hundredths
+	}
+	for _, tt := range tests {
+		got, err := parsePsTime(tt.s)
+		if err != nil {
+			t.Errorf("parsePsTime(%q) failed: %v", tt.s, err)
+		} else if got != tt.want {
+			t.Errorf("parsePsTime(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "x:05", "a-01:00"} {
+		if _, err := parsePsTime(s); err == nil {
+			t.Errorf("parsePsTime(%q) succeeded, want an error", s)
+		}
+	}
+}
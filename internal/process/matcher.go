@@ -0,0 +1,81 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matcher decides which processes are agents. A process matches if its
+// command name is one of Names, its full command line matches one of
+// Patterns, or its executable is one of Paths (or inside one, for
+// directories).
+type Matcher struct {
+	Names    []string
+	Patterns []*regexp.Regexp
+	Paths    []string
+}
+
+// NewMatcher builds a matcher from command names, command line regular
+// expressions and executable paths, as configured in agent_processes,
+// agent_cmdline_patterns and agent_exe_paths. A leading ~ in a path is
+// expanded to the home directory.
+func NewMatcher(names, patterns, paths []string) (*Matcher, error) {
+	m := &Matcher{Names: names}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid agent pattern %q: %w", pattern, err)
+		}
+		m.Patterns = append(m.Patterns, re)
+	}
+
+	home, _ := os.UserHomeDir()
+	for _, path := range paths {
+		if home != "" && (path == "~" || strings.HasPrefix(path, "~/")) {
+			path = filepath.Join(home, path[1:])
+		}
+		m.Paths = append(m.Paths, filepath.Clean(path))
+	}
+	return m, nil
+}
+
+// Match reports whether p is an agent, along with a short name for it.
+func (m *Matcher) Match(p Process) (string, bool) {
+	for _, name := range m.Names {
+		if p.hasName(name) {
+			return name, true
+		}
+	}
+
+	if p.Exe != "" {
+		for _, path := range m.Paths {
+			if p.Exe == path || isInDirectory(p.Exe, path) {
+				return filepath.Base(p.Exe), true
+			}
+		}
+	}
+
+	if len(p.Args) > 0 {
+		cmdline := strings.Join(p.Args, " ")
+		for _, re := range m.Patterns {
+			if match := re.FindString(cmdline); match != "" {
+				return patternLabel(match), true
+			}
+		}
+	}
+	return "", false
+}
+
+// patternLabel turns the text a pattern matched into a short name: the
+// base name of its last word, so "-m aider" gives "aider" and
+// "/usr/lib/node_modules/@openai/codex" gives "codex".
+func patternLabel(match string) string {
+	fields := strings.Fields(match)
+	if len(fields) == 0 {
+		return strings.TrimSpace(match)
+	}
+	return filepath.Base(fields[len(fields)-1])
+}
@@ -0,0 +1,62 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/WillCMcC/ws/internal/config"
+)
+
+// defaultMatcher returns the matcher for the default agent settings.
+func defaultMatcher(t *testing.T) *Matcher {
+	t.Helper()
+	status := config.DefaultConfig().Status
+	m, err := NewMatcher(status.AgentProcesses, status.AgentPatterns, []string{"/opt/agents", "/usr/local/bin/goose"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMatch(t *testing.T) {
+	m := defaultMatcher(t)
+	tests := []struct {
+		proc Process
+		want string // "" for no match
+	}{
+		{Process{Name: "claude", Args: []string{"claude"}}, "claude"},
+		// Wrappers, matched by command line
+		{Process{Name: "node", Args: []string{"node", "/usr/lib/node_modules/@anthropic-ai/claude-code/node_modules/.bin/claude", "--resume"}}, "claude"},
+		{Process{Name: "node", Args: []string{"/home/me/.nvm/versions/node/v22/bin/node", "/home/me/.npm/bin/codex"}}, "codex"},
+		{Process{Name: "python3.12", Args: []string{"/usr/bin/python3.12", "-m", "aider", "--yes"}}, "aider"},
+		// By executable, exactly or inside a directory
+		{Process{Name: "goose", Exe: "/usr/local/bin/goose"}, "goose"},
+		{Process{Name: "helper", Exe: "/opt/agents/bin/helper"}, "helper"},
+		// Not agents
+		{Process{Name: "node", Args: []string{"node", "server.js"}}, ""},
+		{Process{Name: "python3", Args: []string{"python3", "-m", "aiderx"}}, ""},
+		{Process{Name: "vim", Args: []string{"vim", "claude.md"}}, ""},
+		{Process{Name: "goose", Exe: "/usr/local/bin/goose-old"}, ""},
+	}
+	for _, tt := range tests {
+		name, ok := m.Match(tt.proc)
+		if ok != (tt.want != "") || name != tt.want {
+			t.Errorf("Match(%s) = %q, %v; want %q", tt.proc.CommandLine(), name, ok, tt.want)
+		}
+	}
+}
+
+func TestPatternLabel(t *testing.T) {
+	tests := []struct {
+		match, want string
+	}{
+		{"-m aider", "aider"},
+		{"node /usr/lib/node_modules/@openai/codex", "codex"},
+		{"claude-code/cli.js", "cli.js"},
+		{" ", ""},
+	}
+	for _, tt := range tests {
+		if got := patternLabel(tt.match); got != tt.want {
+			t.Errorf("patternLabel(%q) = %q, want %q", tt.match, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package process
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+var errSignalsUnsupported = errors.New("signals are not supported on this platform")
+
+// ParseSignal parses a signal name. Signals are unsupported here.
+func ParseSignal(s string) (syscall.Signal, error) {
+	return 0, errSignalsUnsupported
+}
+
+// Stop stops processes. Signals are unsupported here.
+func Stop(pids []int, sig syscall.Signal, grace time.Duration) (stopped, killed []int, err error) {
+	return nil, nil, errSignalsUnsupported
+}
@@ -0,0 +1,85 @@
+//go:build unix
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ParseSignal parses a signal given by name ("TERM", "SIGINT") or number.
+func ParseSignal(s string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return syscall.Signal(n), nil
+	}
+	name := strings.ToUpper(s)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if sig := unix.SignalNum(name); sig != 0 {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("unknown signal %q", s)
+}
+
+// Stop sends sig to each process and waits up to grace for them to exit,
+// then sends SIGKILL to any still running. It returns the PIDs it stopped,
+// and of those the ones that had to be killed. Processes that have already
+// exited are skipped.
+func Stop(pids []int, sig syscall.Signal, grace time.Duration) (stopped, killed []int, err error) {
+	var errs []error
+	var running []int
+	for _, pid := range pids {
+		if err := syscall.Kill(pid, sig); err != nil {
+			if !errors.Is(err, syscall.ESRCH) {
+				errs = append(errs, fmt.Errorf("pid %d: %w", pid, err))
+			}
+			continue
+		}
+		running = append(running, pid)
+	}
+
+	signalled := running
+	deadline := time.Now().Add(grace)
+	for len(running) > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		running = alive(running)
+	}
+
+	for _, pid := range signalled {
+		if !slices.Contains(running, pid) {
+			stopped = append(stopped, pid)
+		}
+	}
+	for _, pid := range running {
+		if err := syscall.Kill(pid, syscall.SIGKILL); err != nil {
+			if !errors.Is(err, syscall.ESRCH) {
+				errs = append(errs, fmt.Errorf("pid %d: %w", pid, err))
+			}
+			continue
+		}
+		stopped = append(stopped, pid)
+		killed = append(killed, pid)
+	}
+	return stopped, killed, errors.Join(errs...)
+}
+
+// alive returns the PIDs that are still running. A zombie, which has
+// exited but not been reaped by its parent, is not.
+func alive(pids []int) []int {
+	var running []int
+	for _, pid := range pids {
+		err := syscall.Kill(pid, 0)
+		if (err == nil || errors.Is(err, syscall.EPERM)) && !zombie(pid) {
+			running = append(running, pid)
+		}
+	}
+	return running
+}
@@ -0,0 +1,69 @@
+//go:build unix
+
+package process
+
+import (
+	"os/exec"
+	"runtime"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// start starts a command that the test doesn't reap, so it lingers as a
+// zombie once it exits, as an agent's orphaned helper might.
+func start(t *testing.T, script string) int {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", script)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	return cmd.Process.Pid
+}
+
+func TestStop(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("zombies are only told apart from running processes on Linux")
+	}
+
+	exits := start(t, "exec sleep 30")
+	ignores := start(t, `trap "" TERM; exec sleep 30`)
+	time.Sleep(100 * time.Millisecond) // Let the trap be set
+	const gone = 1 << 22               // Above pid_max, so never running
+
+	begin := time.Now()
+	stopped, killed, err := Stop([]int{exits, ignores, gone}, syscall.SIGTERM, time.Second)
+	if err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 3*time.Second {
+		t.Errorf("Stop took %s", elapsed)
+	}
+
+	slices.Sort(stopped)
+	if want := []int{min(exits, ignores), max(exits, ignores)}; !slices.Equal(stopped, want) {
+		t.Errorf("stopped = %v, want %v", stopped, want)
+	}
+	if !slices.Equal(killed, []int{ignores}) {
+		t.Errorf("killed = %v, want [%d]", killed, ignores)
+	}
+}
+
+func TestAliveZombie(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("zombies are only told apart from running processes on Linux")
+	}
+
+	running := start(t, "exec sleep 30")
+	exited := start(t, "exit 0")
+	time.Sleep(200 * time.Millisecond)
+
+	if got := alive([]int{running, exited}); !slices.Equal(got, []int{running}) {
+		t.Errorf("alive = %v, want [%d]", got, running)
+	}
+}
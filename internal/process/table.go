@@ -0,0 +1,193 @@
+package process
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Process is a single entry in the process table.
+type Process struct {
+	PID  int
+	PPID int
+	Name string   // Command name, possibly truncated by the kernel
+	Args []string // Command line
+	Exe  string   // Path of the executable; empty if it couldn't be read
+	Cwd  string   // Working directory; empty if it couldn't be read
+
+	RSS     int64         // Resident memory in bytes
+	CPUTime time.Duration // User and system time used so far
+	Started time.Time
+
+	// ReadingTTY is set for a process blocked reading its terminal, which
+	// for an agent means it is waiting for input. Only Linux reports it.
+	ReadingTTY bool
+}
+
+// CommandLine returns the process's arguments joined with spaces, or its
+// name if the arguments can't be read.
+func (p Process) CommandLine() string {
+	if len(p.Args) == 0 {
+		return p.Name
+	}
+	return strings.Join(p.Args, " ")
+}
+
+// Uptime returns how long the process has been running.
+func (p Process) Uptime() time.Duration {
+	if p.Started.IsZero() {
+		return 0
+	}
+	return time.Since(p.Started)
+}
+
+// CPUPercent returns the share of one CPU the process has used over its
+// lifetime, as ps reports it.
+func (p Process) CPUPercent() float64 {
+	uptime := p.Uptime()
+	if uptime <= 0 {
+		return 0
+	}
+	return 100 * float64(p.CPUTime) / float64(uptime)
+}
+
+// Table is a snapshot of the process table. Take one per command with
+// Snapshot and query it for every workspace, rather than rescanning.
+type Table struct {
+	procs []Process
+	byPID map[int]Process
+}
+
+// Snapshot reads the process table using the platform's inspector.
+func Snapshot() (*Table, error) {
+	return SnapshotWith(NewInspector())
+}
+
+// SnapshotWith reads the process table using the given inspector.
+func SnapshotWith(inspector ProcessInspector) (*Table, error) {
+	procs, err := inspector.Processes()
+	if err != nil {
+		return nil, err
+	}
+	return NewTable(procs), nil
+}
+
+// NewTable builds a table from a list of processes.
+func NewTable(procs []Process) *Table {
+	sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+
+	t := &Table{
+		procs: procs,
+		byPID: make(map[int]Process, len(procs)),
+	}
+	for _, p := range procs {
+		t.byPID[p.PID] = p
+	}
+	return t
+}
+
+// Get returns the process with the given PID.
+func (t *Table) Get(pid int) (Process, bool) {
+	p, ok := t.byPID[pid]
+	return p, ok
+}
+
+// InDir returns the processes whose working directory is dir or inside it,
+// ordered by PID.
+func (t *Table) InDir(dir string) []Process {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	var procs []Process
+	for _, p := range t.procs {
+		if p.Cwd != "" && isInDirectory(p.Cwd, absDir) {
+			procs = append(procs, p)
+		}
+	}
+	return procs
+}
+
+// Agents returns the agents running in dir, ordered by PID.
+//
+// Agents often run as wrappers around other processes (npx, node, a
+// Python module) and spawn helpers of their own, so an agent is the
+// topmost process in dir that m matches; every process beneath it, matched
+// or not, is attributed to it as a child rather than reported separately.
+func (t *Table) Agents(dir string, m *Matcher) []AgentProcess {
+	inDir := t.InDir(dir)
+	inDirPIDs := make(map[int]bool, len(inDir))
+	for _, p := range inDir {
+		inDirPIDs[p.PID] = true
+	}
+
+	var agents []AgentProcess
+	index := make(map[int]int) // agent PID -> position in agents
+	for _, p := range inDir {
+		topPID, name := 0, ""
+		for _, a := range t.lineage(p) {
+			if !inDirPIDs[a.PID] {
+				continue
+			}
+			if label, ok := m.Match(a); ok {
+				topPID, name = a.PID, label
+			}
+		}
+		if topPID == 0 {
+			continue
+		}
+
+		i, seen := index[topPID]
+		if !seen {
+			i = len(agents)
+			index[topPID] = i
+			agents = append(agents, AgentProcess{Name: name, PID: topPID})
+		}
+		if p.PID != topPID {
+			agents[i].Children = append(agents[i].Children, p.PID)
+		}
+	}
+
+	sort.Slice(agents, func(i, j int) bool { return agents[i].PID < agents[j].PID })
+	return agents
+}
+
+// Lineage returns the process with the given PID followed by its
+// ancestors, nearest first.
+func (t *Table) Lineage(pid int) []Process {
+	p, ok := t.byPID[pid]
+	if !ok {
+		return nil
+	}
+	return t.lineage(p)
+}
+
+// lineage returns p followed by its ancestors, nearest first.
+func (t *Table) lineage(p Process) []Process {
+	chain := []Process{p}
+	seen := map[int]bool{p.PID: true}
+	for {
+		parent, ok := t.byPID[p.PPID]
+		if !ok || seen[parent.PID] {
+			return chain
+		}
+		chain = append(chain, parent)
+		seen[parent.PID] = true
+		p = parent
+	}
+}
+
+// hasName reports whether the process is called name. Kernels truncate
+// command names (to 15 bytes on Linux), so for long names a truncated
+// match is confirmed against argv[0].
+func (p Process) hasName(name string) bool {
+	if p.Name == name {
+		return true
+	}
+	if len(p.Name) < 15 || !strings.HasPrefix(name, p.Name) {
+		return false
+	}
+	return len(p.Args) > 0 && filepath.Base(p.Args[0]) == name
+}
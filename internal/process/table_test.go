@@ -0,0 +1,129 @@
+package process
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// fakeInspector is a ProcessInspector returning a fixed process list.
+type fakeInspector struct {
+	procs []Process
+	err   error
+}
+
+func (f fakeInspector) Processes() ([]Process, error) {
+	return f.procs, f.err
+}
+
+func TestSnapshotWith(t *testing.T) {
+	table, err := SnapshotWith(fakeInspector{procs: []Process{
+		{PID: 30, PPID: 20, Name: "sleep", Cwd: "/src/app/.worktrees/app/auth/web"},
+		{PID: 20, PPID: 1, Name: "zsh", Cwd: "/src/app/.worktrees/app/auth"},
+		{PID: 40, PPID: 1, Name: "zsh", Cwd: "/src/app/.worktrees/app/authz"},
+		{PID: 50, PPID: 1, Name: "nginx"}, // Cwd unreadable
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p, ok := table.Get(30); !ok || p.Name != "sleep" {
+		t.Errorf("Get(30) = %v, %v", p, ok)
+	}
+	if _, ok := table.Get(99); ok {
+		t.Errorf("Get(99) found a process")
+	}
+
+	// In the directory or below it, by PID, and not in a sibling sharing
+	// its prefix
+	var pids []int
+	for _, p := range table.InDir("/src/app/.worktrees/app/auth") {
+		pids = append(pids, p.PID)
+	}
+	if len(pids) != 2 || pids[0] != 20 || pids[1] != 30 {
+		t.Errorf("InDir = %v, want [20 30]", pids)
+	}
+
+	var lineage []int
+	for _, p := range table.Lineage(30) {
+		lineage = append(lineage, p.PID)
+	}
+	if len(lineage) != 2 || lineage[0] != 30 || lineage[1] != 20 {
+		t.Errorf("Lineage(30) = %v, want [30 20]", lineage)
+	}
+
+	failure := errors.New("ps not found")
+	if _, err := SnapshotWith(fakeInspector{err: failure}); !errors.Is(err, failure) {
+		t.Errorf("SnapshotWith error = %v, want %v", err, failure)
+	}
+}
+
+func TestHasName(t *testing.T) {
+	tests := []struct {
+		proc Process
+		name string
+		want bool
+	}{
+		{Process{Name: "claude"}, "claude", true},
+		{Process{Name: "claude"}, "claude-code", false},
+		// Linux keeps the first 15 bytes of the name, so argv[0] decides
+		{Process{Name: "cursor-agent-cl", Args: []string{"/opt/bin/cursor-agent-cli", "--yes"}}, "cursor-agent-cli", true},
+		{Process{Name: "cursor-agent-cl", Args: []string{"/opt/bin/cursor-agent-clone"}}, "cursor-agent-cli", false},
+		{Process{Name: "cursor-agent-cl"}, "cursor-agent-cli", false},
+		// Short names aren't truncated, so a prefix is just a different name
+		{Process{Name: "code", Args: []string{"codex"}}, "codex", false},
+	}
+	for _, tt := range tests {
+		if got := tt.proc.hasName(tt.name); got != tt.want {
+			t.Errorf("%+v hasName(%q) = %v, want %v", tt.proc, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAgents(t *testing.T) {
+	const ws = "/src/app/.worktrees/app/auth"
+	table := NewTable([]Process{
+		{PID: 100, PPID: 1, Name: "zsh", Cwd: ws},
+		// npx starts node running claude, which starts a shell and a tool
+		{PID: 101, PPID: 100, Name: "npm exec", Args: []string{"npm", "exec", "claude"}, Cwd: ws},
+		{PID: 102, PPID: 101, Name: "node", Args: []string{"node", "/usr/lib/node_modules/.bin/claude"}, Cwd: ws},
+		{PID: 103, PPID: 102, Name: "bash", Args: []string{"bash", "-c", "go test ./..."}, Cwd: ws},
+		{PID: 104, PPID: 103, Name: "go", Args: []string{"go", "test", "./..."}, Cwd: ws + "/cmd"},
+		// A second, separate agent
+		{PID: 200, PPID: 100, Name: "python3", Args: []string{"python3", "-m", "aider"}, Cwd: ws},
+		// Not an agent
+		{PID: 300, PPID: 100, Name: "vim", Args: []string{"vim", "main.go"}, Cwd: ws},
+		// An agent in another workspace
+		{PID: 400, PPID: 1, Name: "claude", Args: []string{"claude"}, Cwd: "/src/app/.worktrees/app/billing"},
+	})
+
+	agents := table.Agents(ws, defaultMatcher(t))
+	want := []AgentProcess{
+		{Name: "claude", PID: 102, Children: []int{103, 104}},
+		{Name: "aider", PID: 200},
+	}
+	if len(agents) != len(want) {
+		t.Fatalf("Agents = %+v, want %+v", agents, want)
+	}
+	for i := range want {
+		if agents[i].Name != want[i].Name || agents[i].PID != want[i].PID || !slices.Equal(agents[i].Children, want[i].Children) {
+			t.Errorf("agent %d = %+v, want %+v", i, agents[i], want[i])
+		}
+	}
+}
+
+// TestAgentsTopmost checks that an agent started by another agent counts as
+// the outer agent's child.
+func TestAgentsTopmost(t *testing.T) {
+	const ws = "/src/app/.worktrees/app/auth"
+	table := NewTable([]Process{
+		{PID: 10, PPID: 1, Name: "claude", Args: []string{"claude"}, Cwd: ws},
+		{PID: 11, PPID: 10, Name: "codex", Args: []string{"codex", "exec"}, Cwd: ws},
+		{PID: 12, PPID: 11, Name: "rg", Args: []string{"rg", "TODO"}, Cwd: ws},
+	})
+
+	agents := table.Agents(ws, defaultMatcher(t))
+	if len(agents) != 1 || agents[0].PID != 10 || !slices.Equal(agents[0].Children, []int{11, 12}) {
+		t.Errorf("Agents = %+v, want claude (10) with children [11 12]", agents)
+	}
+}
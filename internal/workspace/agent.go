@@ -0,0 +1,147 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/WillCMcC/ws/internal/config"
+	"github.com/mattn/go-isatty"
+)
+
+// agentKeys are the config keys holding commands that start the agent.
+var agentKeys = []string{"agent_cmd", "agent_prompt_template"}
+
+// AgentSetting is an agent command set in the repository's .ws/config.
+// Like a repository hook, it only runs once trusted.
+type AgentSetting struct {
+	Key   string
+	Label string // Where it is set, for display
+	Value string
+}
+
+// Content returns the setting as written in the config. This is what trust
+// approvals are keyed on.
+func (s AgentSetting) Content() []byte {
+	return []byte(s.Key + "=" + s.Value)
+}
+
+// RepoAgentSettings returns the agent commands the repository's config
+// sets.
+func (m *Manager) RepoAgentSettings() []AgentSetting {
+	var settings []AgentSetting
+	for _, key := range agentKeys {
+		origin := m.Config.Origin(key)
+		if origin.Source != config.SourceRepo {
+			continue
+		}
+		if value := m.Config.Value(key); value != "" {
+			settings = append(settings, AgentSetting{
+				Key:   key,
+				Label: key + " in " + origin.Location,
+				Value: value,
+			})
+		}
+	}
+	return settings
+}
+
+// CheckAgentTrust returns nil if the configured agent commands may run.
+// Those set by the repository must be trusted, and are offered for
+// approval when running interactively.
+func (m *Manager) CheckAgentTrust() error {
+	settings := m.RepoAgentSettings()
+	if len(settings) == 0 {
+		return nil
+	}
+
+	store, err := LoadTrustStore()
+	if err != nil {
+		return fmt.Errorf("failed to read trust store: %w", err)
+	}
+	for _, setting := range settings {
+		if store.Trusted(m.RepoRoot, setting) {
+			continue
+		}
+		if !m.approve(store, setting, "its agent command ("+setting.Label+")") {
+			return fmt.Errorf("untrusted %s in the repository config; review it and run 'ws trust' to approve", setting.Key)
+		}
+	}
+	return nil
+}
+
+// AgentLogPath returns the file the output of agents ws runs in the named
+// workspace is appended to. Like metadata, it goes by the last element of
+// the name.
+func (m *Manager) AgentLogPath(name string) (string, error) {
+	dir, err := m.stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logs", filepath.Base(name)+".log"), nil
+}
+
+// agentCommand returns the command that runs command, the agent, in ws.
+// On a terminal it runs under script(1) where available, which records its
+// output to the agent log while the agent keeps a terminal of its own.
+func (m *Manager) agentCommand(ws *Workspace, command string) *exec.Cmd {
+	unrecorded := exec.Command("sh", "-c", command)
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return unrecorded
+	}
+	script, err := exec.LookPath("script")
+	if err != nil {
+		return unrecorded
+	}
+	path, err := m.AgentLogPath(ws.Name)
+	if err != nil {
+		return unrecorded
+	}
+	args := scriptArgs(path, command)
+	if args == nil {
+		return unrecorded
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create agent log: %v\n", err)
+		return unrecorded
+	}
+	return exec.Command(script, args...)
+}
+
+// RunAgent runs command, the agent, in ws as a child of ws sharing its
+// terminal, and returns the agent's exit code once it exits. Its output
+// is recorded to the agent log as well. The post-agent hook runs
+// afterwards. While the agent runs, ws ignores interrupts so that Ctrl-C
+// reaches only the agent.
+func (m *Manager) RunAgent(ws *Workspace, command string) (int, error) {
+	cmd := m.agentCommand(ws, command)
+	cmd.Dir = ws.Path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	signal.Ignore(os.Interrupt)
+	err := cmd.Run()
+	signal.Reset(os.Interrupt)
+
+	if err := m.RecordAgentExit(ws.Name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save workspace metadata: %v\n", err)
+	}
+
+	code := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return 1, err
+		}
+		code = exitErr.ExitCode()
+	}
+
+	if err := m.RunHook(EventPostAgent, ws.Name, ws.Path); err != nil {
+		return code, err
+	}
+	return code, nil
+}
@@ -0,0 +1,8 @@
+package workspace
+
+// scriptArgs returns the arguments for BSD script(1) to run command while
+// appending what it writes to the terminal to log, flushed after every
+// write so the log can be followed.
+func scriptArgs(log, command string) []string {
+	return []string{"-q", "-a", "-t", "0", log, "sh", "-c", command}
+}
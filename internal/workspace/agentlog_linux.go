@@ -0,0 +1,9 @@
+package workspace
+
+// scriptArgs returns the arguments for util-linux script(1) to run command
+// while appending what it writes to the terminal to log, flushed as it
+// goes so the log can be followed. script hands -c to the user's shell, so
+// the command is quoted for sh to run as it would unrecorded.
+func scriptArgs(log, command string) []string {
+	return []string{"-q", "-e", "-f", "-a", "-c", "exec sh -c " + ShellQuote(command), log}
+}
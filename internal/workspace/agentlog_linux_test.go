@@ -0,0 +1,42 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScriptArgs(t *testing.T) {
+	if _, err := exec.LookPath("script"); err != nil {
+		t.Skip("script not installed")
+	}
+	log := filepath.Join(t.TempDir(), "agent.log")
+
+	runs := 0
+	for _, shell := range []string{"/bin/sh", "/bin/bash"} {
+		if _, err := os.Stat(shell); err != nil {
+			continue
+		}
+		cmd := exec.Command("script", scriptArgs(log, `printf '%s\n' "it's $0"; exit 3`)...)
+		cmd.Env = append(os.Environ(), "SHELL="+shell)
+		err := cmd.Run()
+		runs++
+
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+			t.Errorf("with SHELL=%s: err = %v, want exit status 3", shell, err)
+		}
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each run appends to the log
+	if n := strings.Count(string(data), "it's sh"); n != runs {
+		t.Errorf("log has the command's output %d times, want %d:\n%s", n, runs, data)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package workspace
+
+// scriptArgs returns nil where ws doesn't know how to drive script(1);
+// agents run unrecorded there.
+func scriptArgs(log, command string) []string {
+	return nil
+}
@@ -0,0 +1,126 @@
+package workspace
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// copyLocalFiles copies and symlinks the untracked files configured in
+// copy_files and link_files from the main worktree into a new workspace,
+// so it is runnable without a manual setup step. Files the checkout
+// already contains are left alone. Failures are reported as warnings.
+func (m *Manager) copyLocalFiles(wsPath string) {
+	copied := m.forEachLocalFile(m.Config.Workspace.CopyFiles, wsPath, func(src, dst string) error {
+		return copyPath(src, dst)
+	})
+	linked := m.forEachLocalFile(m.Config.Workspace.LinkFiles, wsPath, func(src, dst string) error {
+		return os.Symlink(src, dst)
+	})
+
+	if copied > 0 || linked > 0 {
+		fmt.Printf("Copied %d and linked %d local file(s) into workspace\n", copied, linked)
+	}
+}
+
+// forEachLocalFile expands patterns against the repo root and calls fn
+// with each match and its destination in wsPath. It returns how many
+// matches fn handled successfully.
+func (m *Manager) forEachLocalFile(patterns []string, wsPath string, fn func(src, dst string) error) int {
+	wsDir := m.Config.GetWorkspaceDir(m.RepoRoot)
+	gitDir := filepath.Join(m.RepoRoot, ".git")
+
+	count := 0
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(m.RepoRoot, pattern))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid file pattern %q: %v\n", pattern, err)
+			continue
+		}
+
+		for _, src := range matches {
+			// Never copy the workspaces themselves or git's own data
+			if isInDirectory(src, wsDir) || isInDirectory(src, gitDir) {
+				continue
+			}
+
+			rel, err := filepath.Rel(m.RepoRoot, src)
+			if err != nil {
+				continue
+			}
+			dst := filepath.Join(wsPath, rel)
+
+			// Tracked files are already checked out
+			if _, err := os.Lstat(dst); err == nil {
+				continue
+			}
+
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to create %s: %v\n", filepath.Dir(dst), err)
+				continue
+			}
+			if err := fn(src, dst); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to set up %s: %v\n", rel, err)
+				continue
+			}
+			count++
+		}
+	}
+	return count
+}
+
+// copyPath copies a file, symlink or directory tree from src to dst,
+// preserving file modes.
+func copyPath(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		// Skip sockets, devices and other special files
+		return nil
+	})
+}
+
+// copyFile copies a regular file's contents to dst with the given mode.
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,46 @@
+package workspace
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FuzzyScore reports whether the characters of pattern appear in s in
+// order, ignoring case, and scores the match: the higher the better.
+// Characters matched consecutively, or at the start of s or of a word
+// within it, score more, so "auth" ranks "auth-fix" above "oauth" above
+// "a-u-t-h".
+func FuzzyScore(pattern, s string) (int, bool) {
+	pattern, s = strings.ToLower(pattern), strings.ToLower(s)
+	length := utf8.RuneCountInString(s)
+
+	score := 0
+	prev := rune(-1)     // The character of s before the one being compared
+	consecutive := false // Whether the previous character of s was matched
+	for _, want := range pattern {
+		found := false
+		for s != "" {
+			r, size := utf8.DecodeRuneInString(s)
+			s = s[size:]
+			if r != want {
+				prev, consecutive = r, false
+				continue
+			}
+
+			score++
+			if consecutive {
+				score += 3
+			} else if prev == -1 || !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				score += 2 // Start of s or of a word
+			}
+			prev, consecutive, found = r, true, true
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	// Prefer tighter names among otherwise equal matches
+	return score*100 - length, true
+}
@@ -1,20 +1,297 @@
 package workspace
 
 import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/config"
+	"github.com/WillCMcC/ws/internal/git"
+	"github.com/mattn/go-isatty"
+)
+
+// Event names a point in a workspace's lifecycle at which a hook can run.
+type Event string
+
+const (
+	EventPreCreate        Event = "pre-create"
+	EventPostCreate       Event = "post-create"
+	EventPreRemove        Event = "pre-remove"
+	EventPostRemove       Event = "post-remove"
+	EventPreFold          Event = "pre-fold"
+	EventPostFold         Event = "post-fold"
+	EventOnRebaseConflict Event = "on-rebase-conflict"
+	EventPreAgent         Event = "pre-agent"
+	EventPostAgent        Event = "post-agent"
 )
 
-// runHook executes a hook command in the specified directory.
-func runHook(command, dir, name string) error {
-	// Replace placeholders
-	command = strings.ReplaceAll(command, "{name}", name)
-	command = strings.ReplaceAll(command, "{path}", dir)
+// Events lists every hook event in lifecycle order.
+var Events = []Event{
+	EventPreCreate,
+	EventPostCreate,
+	EventPreRemove,
+	EventPostRemove,
+	EventPreFold,
+	EventPostFold,
+	EventOnRebaseConflict,
+	EventPreAgent,
+	EventPostAgent,
+}
+
+// ParseEvent returns the event with the given name.
+func ParseEvent(name string) (Event, error) {
+	for _, event := range Events {
+		if string(event) == name {
+			return event, nil
+		}
+	}
+	return "", fmt.Errorf("unknown hook event '%s'", name)
+}
+
+// RepoHooksDir is the directory, relative to the repo root, holding hook
+// scripts provided by the repository. Each script is named after its event,
+// e.g. .ws/hooks/post-create, and must be executable.
+const RepoHooksDir = ".ws/hooks"
+
+// HookContext describes the workspace a hook runs for. It is exposed to
+// the hook through WS_* environment variables.
+type HookContext struct {
+	Name   string
+	Path   string
+	Branch string
+	Base   string
+}
+
+// HookSource is a single hook script or configured command for an event.
+type HookSource struct {
+	Event    Event
+	Label    string // Where the hook is defined, for display
+	Script   string // Path of a hook script; empty for configured commands
+	Command  string // Configured shell command; empty for scripts
+	FromRepo bool   // Provided by the repository, so it must be trusted
+}
+
+// Content returns what the hook will run: the script's contents or the
+// configured command. This is what trust approvals are keyed on.
+func (h HookSource) Content() []byte {
+	if h.Script != "" {
+		data, err := os.ReadFile(h.Script)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+	return []byte(h.Command)
+}
+
+// HookSources returns the hooks that run for event, in order: the
+// repository's hook script, then the configured command.
+func (m *Manager) HookSources(event Event) []HookSource {
+	var sources []HookSource
+
+	script := filepath.Join(m.RepoRoot, RepoHooksDir, string(event))
+	if info, err := os.Stat(script); err == nil && info.Mode().IsRegular() {
+		if info.Mode().Perm()&0111 != 0 {
+			sources = append(sources, HookSource{
+				Event:    event,
+				Label:    filepath.Join(RepoHooksDir, string(event)),
+				Script:   script,
+				FromRepo: true,
+			})
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %s is not executable and will not run\n", filepath.Join(RepoHooksDir, string(event)))
+		}
+	}
+
+	if command := m.Config.Hooks.Get(string(event)).Command; command != "" {
+		key := config.HookKey(string(event))
+		origin := m.Config.Origin(key)
+		label := key + " in " + origin.Location
+		if origin.Source == config.SourceEnv {
+			label = origin.Location
+		}
+		sources = append(sources, HookSource{
+			Event:    event,
+			Label:    label,
+			Command:  command,
+			FromRepo: origin.Source == config.SourceRepo,
+		})
+	}
+
+	return sources
+}
+
+// RunHook runs the hooks for event, if any, for the workspace name at
+// path. It returns an error only when a hook fails and its policy is
+// abort; with the default warn policy a failure is printed as a warning,
+// and with ignore it is dropped.
+func (m *Manager) RunHook(event Event, name, path string) error {
+	sources := m.HookSources(event)
+	if len(sources) == 0 {
+		return nil
+	}
+
+	branch := name
+	if b, err := git.GetBranch(path); err == nil {
+		branch = b
+	}
+	// Hooks see the base the workspace was created from
+	meta, _ := m.LoadMeta(name)
+
+	return m.runHooks(event, sources, HookContext{
+		Name:   name,
+		Path:   path,
+		Branch: branch,
+		Base:   cmp.Or(meta.BaseRef, m.Config.GetDefaultBase()),
+	})
+}
+
+// runHook runs every hook for event.
+func (m *Manager) runHook(event Event, hc HookContext) error {
+	return m.runHooks(event, m.HookSources(event), hc)
+}
+
+// runHooks runs sources in order, applying the event's failure policy.
+// Hooks run inside the workspace, or in the main repository when the
+// workspace directory does not exist (pre-create, post-remove).
+// Repository-provided hooks only run once trusted, and none run with
+// WS_NO_HOOKS=1.
+func (m *Manager) runHooks(event Event, sources []HookSource, hc HookContext) error {
+	if len(sources) == 0 || config.HooksDisabled() {
+		return nil
+	}
+	policy := m.Config.Hooks.Get(string(event)).Policy
+
+	dir := hc.Path
+	if info, err := os.Stat(hc.Path); err != nil || !info.IsDir() {
+		dir = m.RepoRoot
+	}
+
+	env := append(os.Environ(),
+		"WS_NAME="+hc.Name,
+		"WS_PATH="+hc.Path,
+		"WS_BRANCH="+hc.Branch,
+		"WS_BASE="+hc.Base,
+		"WS_REPO_ROOT="+m.RepoRoot,
+		"WS_EVENT="+string(event),
+	)
+
+	for _, source := range sources {
+		start := time.Now()
+		err := m.checkTrust(source)
+		if err == nil {
+			err = runHook(source, dir, hc, env, m.Config.Hooks.Timeout)
+		}
+		// Hooks that run before the workspace exists or after it is gone
+		// have nowhere to be recorded
+		if dir != m.RepoRoot {
+			m.recordHook(hc.Name, event, source, start, err)
+		}
+		if err == nil {
+			continue
+		}
+
+		switch policy {
+		case config.PolicyIgnore:
+			// Carry on silently
+		case config.PolicyAbort:
+			return fmt.Errorf("%s hook %s failed: %w", event, source.Label, err)
+		default:
+			fmt.Fprintf(os.Stderr, "Warning: %s hook %s failed: %v\n", event, source.Label, err)
+		}
+	}
+	return nil
+}
+
+// checkTrust returns nil if source may run. Untrusted repository hooks
+// are offered for approval when running interactively and refused
+// otherwise.
+func (m *Manager) checkTrust(source HookSource) error {
+	if !source.FromRepo {
+		return nil
+	}
+
+	store, err := LoadTrustStore()
+	if err != nil {
+		return fmt.Errorf("failed to read trust store: %w", err)
+	}
+	if store.Trusted(m.RepoRoot, source) {
+		return nil
+	}
+
+	if !m.approve(store, source, fmt.Sprintf("a %s hook (%s)", source.Event, source.Label)) {
+		return fmt.Errorf("untrusted repository hook; review it and run 'ws trust' to approve")
+	}
+	return nil
+}
+
+// approve shows item, described by what, and asks the user whether to
+// trust it, saving the approval if they do. It reports whether item may
+// run; without a user to ask it may not.
+func (m *Manager) approve(store *TrustStore, item Trustable, what string) bool {
+	if !isInteractive() {
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "This repository provides %s:\n\n", what)
+	for _, line := range strings.Split(strings.TrimRight(string(item.Content()), "\n"), "\n") {
+		fmt.Fprintf(os.Stderr, "    %s\n", line)
+	}
+	fmt.Fprint(os.Stderr, "\nTrust and run it? [y/N] ")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "y" && response != "yes" {
+		return false
+	}
+
+	store.Trust(m.RepoRoot, item)
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save trust store: %v\n", err)
+	}
+	return true
+}
+
+// isInteractive reports whether a user is present to answer prompts.
+func isInteractive() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stderr.Fd())
+}
+
+// runHook executes a single hook in dir, killing it and everything it
+// started if it runs longer than timeout. Scripts receive the workspace
+// name and path as arguments; commands get {name} and {path} substituted.
+func runHook(source HookSource, dir string, hc HookContext, env []string, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	cmd := exec.Command("sh", "-c", command)
+	var cmd *exec.Cmd
+	if source.Script != "" {
+		cmd = exec.CommandContext(ctx, source.Script, hc.Name, hc.Path)
+	} else {
+		// Replace placeholders
+		command := strings.ReplaceAll(source.Command, "{name}", hc.Name)
+		command = strings.ReplaceAll(command, "{path}", hc.Path)
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
 	cmd.Dir = dir
+	cmd.Env = env
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	err := runProcessGroup(cmd)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
 }
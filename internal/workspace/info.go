@@ -0,0 +1,189 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/git"
+	"github.com/WillCMcC/ws/internal/process"
+)
+
+// InfoVersion is the version of the Info schema. It changes only when a
+// field is removed or changes meaning; new fields may appear at any time.
+const InfoVersion = 1
+
+// Info is everything ws reports about a workspace. It is the data model
+// behind 'ws list' and 'ws status', and its JSON encoding is their --json
+// output.
+type Info struct {
+	Name       string                 `json:"name"`
+	Branch     string                 `json:"branch"`
+	Path       string                 `json:"path"`
+	State      State                  `json:"state"`
+	Base       string                 `json:"base"`
+	Ahead      int                    `json:"ahead"`               // Commits on the branch not on Base
+	Behind     int                    `json:"behind"`              // Commits on Base not on the branch
+	Dirty      int                    `json:"dirty"`               // Files with uncommitted changes
+	LastCommit Commit                 `json:"last_commit"`         // Zero when unknown
+	Operation  string                 `json:"operation,omitempty"` // Git operation in progress, e.g. "rebase"
+	Rebasing   bool                   `json:"rebase_in_progress"`
+	Locked     bool                   `json:"locked"`
+	LockReason string                 `json:"lock_reason,omitempty"`
+	Agents     []process.AgentProcess `json:"agents"`
+	Processes  []ProcessInfo          `json:"processes"`
+	Meta       Meta                   `json:"meta"`
+	Error      string                 `json:"error,omitempty"` // Why some fields are missing
+}
+
+// Commit describes a commit. The zero Commit stands for one that isn't
+// known, as when the branch has no commits or reading it timed out, so that
+// --format templates can use its fields without checking for it.
+type Commit struct {
+	SHA     string    `json:"sha"`
+	Subject string    `json:"subject"`
+	Time    time.Time `json:"time"`
+}
+
+// IsZero reports whether the commit is unknown.
+func (c Commit) IsZero() bool {
+	return c.SHA == ""
+}
+
+// MarshalJSON encodes an unknown commit as null.
+func (c Commit) MarshalJSON() ([]byte, error) {
+	if c.IsZero() {
+		return []byte("null"), nil
+	}
+	type commit Commit // Without this method
+	return json.Marshal(commit(c))
+}
+
+// ProcessInfo describes a process running inside a workspace.
+type ProcessInfo struct {
+	PID     int    `json:"pid"`
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	Agent   string `json:"agent,omitempty"` // The agent the process belongs to, if any
+}
+
+// inspectWorkers is how many workspaces are inspected at once.
+const inspectWorkers = 8
+
+// InspectAll inspects workspaces concurrently and returns their Info in the
+// same order. Each workspace gets status_timeout to be inspected in, so one
+// slow worktree doesn't hold up the rest; a workspace that runs out of
+// time is reported with what was gathered and an error.
+func (c *StateClassifier) InspectAll(workspaces []Workspace, base string) []Info {
+	infos := make([]Info, len(workspaces))
+	timeout := c.m.Config.Status.Timeout
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(inspectWorkers, len(workspaces)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				infos[i] = c.inspectWithin(workspaces[i], base, timeout)
+			}
+		}()
+	}
+	for i := range workspaces {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return infos
+}
+
+// inspectWithin inspects ws, giving up on whatever is left after timeout.
+// Zero means no timeout.
+func (c *StateClassifier) inspectWithin(ws Workspace, base string, timeout time.Duration) Info {
+	if timeout <= 0 {
+		return c.Inspect(context.Background(), ws, base)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	info := c.Inspect(ctx, ws, base)
+	if ctx.Err() == context.DeadlineExceeded {
+		info.Error = fmt.Sprintf("timed out after %s", timeout)
+	}
+	return info
+}
+
+// Inspect gathers everything reported about ws, comparing its branch with
+// base. The git commands it runs are killed if ctx is done.
+func (c *StateClassifier) Inspect(ctx context.Context, ws Workspace, base string) Info {
+	info := Info{
+		Name:       ws.Name,
+		Branch:     ws.Branch,
+		Path:       ws.Path,
+		Base:       base,
+		Locked:     ws.Locked,
+		LockReason: ws.LockReason,
+		Meta:       ws.Meta,
+	}
+
+	if sha, subject, when, err := git.GetHeadCommit(ctx, ws.Path); err == nil {
+		info.LastCommit = Commit{SHA: sha, Subject: subject, Time: when}
+	}
+
+	status, err := git.GetWorktreeStatusContext(ctx, ws.Path)
+	info.Operation = git.GetOperation(ctx, ws.Path)
+	info.Rebasing = info.Operation == "rebase"
+	if err != nil {
+		info.State = StateUnknown
+		info.Error = fmt.Sprintf("failed to read status: %v", err)
+	} else {
+		info.State = c.classify(ws, status, info.LastCommit)
+		info.Dirty = countLines(status)
+	}
+
+	info.Ahead, info.Behind, _ = git.GetAheadBehind(ctx, ws.Path, base)
+
+	info.Agents = c.Agents(ws)
+	if c.first != nil {
+		owner := make(map[int]string)
+		for _, agent := range info.Agents {
+			owner[agent.PID] = agent.Name
+			for _, pid := range agent.Children {
+				owner[pid] = agent.Name
+			}
+		}
+		for _, p := range Processes(c.first, ws.Path) {
+			info.Processes = append(info.Processes, ProcessInfo{
+				PID:     p.PID,
+				Name:    p.Name,
+				Command: p.CommandLine(),
+				Agent:   owner[p.PID],
+			})
+		}
+	}
+
+	// Empty lists rather than nulls keep the JSON schema uniform
+	if info.Agents == nil {
+		info.Agents = []process.AgentProcess{}
+	}
+	if info.Processes == nil {
+		info.Processes = []ProcessInfo{}
+	}
+	return info
+}
+
+// countLines counts the lines in s, including a final unterminated one.
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	n := strings.Count(s, "\n")
+	if !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
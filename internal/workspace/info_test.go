@@ -0,0 +1,30 @@
+package workspace
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfoLastCommitJSON(t *testing.T) {
+	tests := []struct {
+		commit Commit
+		want   string
+	}{
+		{Commit{}, `"last_commit":null`},
+		{
+			Commit{SHA: "9e1f", Subject: "Add login form", Time: time.Date(2026, 10, 17, 9, 12, 44, 0, time.UTC)},
+			`"last_commit":{"sha":"9e1f","subject":"Add login form","time":"2026-10-17T09:12:44Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(Info{LastCommit: tt.commit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("json.Marshal(%+v) = %s, want it to contain %s", tt.commit, data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/fsutil"
+	"github.com/WillCMcC/ws/internal/git"
+)
+
+// Meta is what ws records about a workspace beyond what git knows: where
+// it came from, who made it and why, and what has happened in it since.
+// It is stored as JSON in <git-common-dir>/ws/workspaces/<name>.json.
+type Meta struct {
+	BaseRef    string       `json:"base_ref,omitempty"`
+	BaseCommit string       `json:"base_commit,omitempty"`
+	Created    time.Time    `json:"created,omitzero"`
+	Creator    string       `json:"creator,omitempty"`
+	AgentCmd   string       `json:"agent_cmd,omitempty"` // Command the agent was last started with
+	Prompt     string       `json:"prompt,omitempty"`    // Task the agent was last started on
+	Notes      []Note       `json:"notes,omitempty"`
+	Hooks      []HookResult `json:"hooks,omitempty"` // Latest result of each hook
+	LastAgent  *AgentRun    `json:"last_agent,omitempty"`
+}
+
+// Note is a free-form note attached to a workspace.
+type Note struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+// HookResult is the outcome of the last run of one hook in a workspace.
+type HookResult struct {
+	Event    Event         `json:"event"`
+	Hook     string        `json:"hook"` // The hook's label, e.g. "post_create in .ws/config"
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// AgentRun records the agent ws last started in a workspace.
+type AgentRun struct {
+	Name   string    `json:"name"`
+	Time   time.Time `json:"time"`            // When it was started
+	Exited time.Time `json:"exited,omitzero"` // When it exited, if ws saw it
+}
+
+// metaPath returns the metadata file for the named workspace. Like List,
+// it goes by the last element of the name, so that a workspace created as
+// feature/x finds its metadata under x.
+func (m *Manager) metaPath(name string) (string, error) {
+	dir, err := m.stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workspaces", filepath.Base(name)+".json"), nil
+}
+
+// lockMeta serializes changes to metadata between ws processes, and the
+// goroutines of one, so that concurrent updates don't lose each other's
+// writes. Call the returned function to release it.
+func (m *Manager) lockMeta() (func(), error) {
+	dir, err := m.stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return fsutil.Lock(filepath.Join(dir, "workspaces.lock"))
+}
+
+// LoadMeta returns the metadata recorded for the named workspace. A
+// workspace without any, such as one created before ws kept metadata, has
+// empty metadata.
+func (m *Manager) LoadMeta(name string) (Meta, error) {
+	var meta Meta
+	path, err := m.metaPath(name)
+	if err != nil {
+		return meta, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return meta, nil
+		}
+		return meta, err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("invalid metadata in %s: %w", path, err)
+	}
+	return meta, nil
+}
+
+// UpdateMeta applies fn to the named workspace's metadata and saves it.
+func (m *Manager) UpdateMeta(name string, fn func(meta *Meta)) error {
+	unlock, err := m.lockMeta()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	meta, err := m.LoadMeta(name)
+	if err != nil {
+		return err
+	}
+	fn(&meta)
+	return m.saveMeta(name, meta)
+}
+
+// saveMeta replaces the named workspace's metadata.
+func (m *Manager) saveMeta(name string, meta Meta) error {
+	path, err := m.metaPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(data, '\n'), 0644)
+}
+
+// AddNote attaches a note to the named workspace.
+func (m *Manager) AddNote(name, text string) error {
+	return m.UpdateMeta(name, func(meta *Meta) {
+		meta.Notes = append(meta.Notes, Note{Time: time.Now(), Text: text})
+	})
+}
+
+// recordCreate writes the metadata for a workspace just created from
+// base, replacing any left behind by an earlier workspace of the same name.
+func (m *Manager) recordCreate(name, wsPath, base string) {
+	meta := Meta{
+		BaseRef: base,
+		Created: time.Now(),
+		Creator: creator(),
+	}
+	meta.BaseCommit, _ = git.GetHead(wsPath)
+	if err := m.UpdateMeta(name, func(old *Meta) { *old = meta }); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save workspace metadata: %v\n", err)
+	}
+}
+
+// RecordAgent notes that an agent is being started with command and
+// prompt.
+func (m *Manager) RecordAgent(name, command, prompt string) error {
+	return m.UpdateMeta(name, func(meta *Meta) {
+		meta.AgentCmd = command
+		if prompt != "" {
+			meta.Prompt = prompt
+		}
+		meta.LastAgent = &AgentRun{Name: agentName(command), Time: time.Now()}
+	})
+}
+
+// RecordAgentExit notes that the agent last started in the named
+// workspace has exited.
+func (m *Manager) RecordAgentExit(name string) error {
+	return m.UpdateMeta(name, func(meta *Meta) {
+		if meta.LastAgent != nil {
+			meta.LastAgent.Exited = time.Now()
+		}
+	})
+}
+
+// agentName returns the program command runs, e.g. claude for
+// "claude --dangerously-skip-permissions", skipping variable assignments.
+func agentName(command string) string {
+	for _, field := range strings.Fields(command) {
+		if !strings.Contains(field, "=") {
+			return filepath.Base(field)
+		}
+	}
+	return command
+}
+
+// recordHook stores the result of a hook run in the named workspace,
+// replacing the previous result of the same hook.
+func (m *Manager) recordHook(name string, event Event, source HookSource, start time.Time, hookErr error) {
+	result := HookResult{
+		Event:    event,
+		Hook:     source.Label,
+		Time:     start,
+		Duration: time.Since(start).Round(time.Millisecond),
+	}
+	if hookErr != nil {
+		result.Error = hookErr.Error()
+	}
+
+	_ = m.UpdateMeta(name, func(meta *Meta) {
+		for i, r := range meta.Hooks {
+			if r.Event == event && r.Hook == source.Label {
+				meta.Hooks[i] = result
+				return
+			}
+		}
+		meta.Hooks = append(meta.Hooks, result)
+	})
+}
+
+// forgetMeta deletes the named workspace's metadata and agent log.
+func (m *Manager) forgetMeta(name string) {
+	if path, err := m.AgentLogPath(name); err == nil {
+		os.Remove(path)
+	}
+
+	unlock, err := m.lockMeta()
+	if err != nil {
+		return
+	}
+	defer unlock()
+	if path, err := m.metaPath(name); err == nil {
+		os.Remove(path)
+	}
+}
+
+// PruneMeta deletes the metadata left behind by workspaces that no longer
+// exist, such as ones removed with plain git, and returns their names.
+// With dryRun it only returns them.
+func (m *Manager) PruneMeta(dryRun bool) ([]string, error) {
+	workspaces, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool)
+	for _, ws := range workspaces {
+		exists[ws.Name] = true
+	}
+
+	dir, err := m.stateDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "workspaces", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		if exists[name] {
+			continue
+		}
+		stale = append(stale, name)
+		if !dryRun {
+			m.forgetMeta(name)
+		}
+	}
+	return stale, nil
+}
+
+// creator identifies who is creating a workspace: the git user name, or
+// the login name if that isn't set.
+func creator() string {
+	if output, err := exec.Command("git", "config", "user.name").Output(); err == nil {
+		if name := strings.TrimSpace(string(output)); name != "" {
+			return name
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package workspace
+
+import "os/exec"
+
+// runProcessGroup just runs cmd where process groups aren't available;
+// cancellation kills only the shell itself.
+func runProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Run()
+}
@@ -0,0 +1,61 @@
+//go:build unix
+
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// runProcessGroup runs cmd in its own process group and makes
+// cancellation kill the whole group, so that children of 'sh -c' (an
+// 'npm install', say) don't outlive a timed-out hook. Out of the
+// terminal's foreground group, the hook would miss a Ctrl-C, so SIGINT
+// and SIGTERM are passed on to the group while it runs and then, once it
+// has exited, to ws itself.
+func runProcessGroup(cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	signals := make(chan os.Signal, 1)
+	for _, sig := range []os.Signal{syscall.SIGINT, syscall.SIGTERM} {
+		// Nor should the hook get a signal ws itself ignores
+		if !signal.Ignored(sig) {
+			signal.Notify(signals, sig)
+		}
+	}
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	received := make(chan syscall.Signal, 1)
+	go func() {
+		var got syscall.Signal
+		defer func() { received <- got }()
+		for {
+			select {
+			case sig := <-signals:
+				got = sig.(syscall.Signal)
+				syscall.Kill(-cmd.Process.Pid, got)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	close(done)
+	if sig := <-received; sig != 0 {
+		// Default handling again, so the signal ends ws as it would have
+		signal.Stop(signals)
+		syscall.Kill(os.Getpid(), sig)
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/process"
+)
+
+// StopGrace is how long processes are given to exit after SIGTERM before
+// they are sent SIGKILL.
+const StopGrace = 5 * time.Second
+
+// Processes returns the processes in table whose working directory is
+// inside the workspace at path, leaving out ws itself and the shell that
+// ran it.
+func Processes(table *process.Table, path string) []process.Process {
+	self := make(map[int]bool)
+	for _, p := range table.Lineage(os.Getpid()) {
+		self[p.PID] = true
+	}
+
+	var procs []process.Process
+	for _, p := range table.InDir(path) {
+		if !self[p.PID] {
+			procs = append(procs, p)
+		}
+	}
+	return procs
+}
+
+// RunningProcesses snapshots the process table and returns the processes
+// running inside the workspace at path.
+func RunningProcesses(path string) ([]process.Process, error) {
+	table, err := process.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process table: %w", err)
+	}
+	return Processes(table, path), nil
+}
+
+// StopProcesses sends sig to procs, escalating to SIGKILL for any still
+// running after grace, and reports what happened.
+func StopProcesses(procs []process.Process, sig syscall.Signal, grace time.Duration) error {
+	pids := make([]int, len(procs))
+	for i, p := range procs {
+		pids[i] = p.PID
+	}
+
+	stopped, killed, err := process.Stop(pids, sig, grace)
+	for _, pid := range killed {
+		fmt.Printf("Killed pid %d (still running after %s)\n", pid, grace)
+	}
+	fmt.Printf("Stopped %d process(es)\n", len(stopped))
+	return err
+}
+
+// printProcesses lists procs, one "PID  name" line each.
+func printProcesses(procs []process.Process) {
+	for _, p := range procs {
+		fmt.Printf("  %d  %s\n", p.PID, p.Name)
+	}
+}
@@ -0,0 +1,173 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WillCMcC/ws/internal/fsutil"
+)
+
+// PreviousName is the name that refers to the workspace visited before
+// the current one, as with 'cd -'.
+const PreviousName = "-"
+
+// NotFoundError is returned by Get and Resolve when no workspace matches a
+// name.
+type NotFoundError struct {
+	Name      string
+	Available []string // The names of all workspaces
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("workspace '%s' not found", e.Name)
+}
+
+// AmbiguousError is returned by Resolve when a name matches more than one
+// workspace, however much better one of the matches is.
+type AmbiguousError struct {
+	Name       string
+	Candidates []string // The names it matches, best first
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("'%s' matches several workspaces: %s", e.Name, strings.Join(e.Candidates, ", "))
+}
+
+// Get returns the workspace with exactly the given name. Names typed by
+// the user go through Resolve instead.
+func (m *Manager) Get(name string) (*Workspace, error) {
+	workspaces, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range workspaces {
+		if workspaces[i].Name == name {
+			return &workspaces[i], nil
+		}
+	}
+	return nil, &NotFoundError{Name: name, Available: names(workspaces)}
+}
+
+// Resolve returns the workspace a name refers to: the workspace with
+// exactly that name, else the only one whose name starts with it, else
+// the only one it fuzzily matches (see FuzzyScore). PreviousName refers
+// to the workspace visited before the current one.
+func (m *Manager) Resolve(name string) (*Workspace, error) {
+	workspaces, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == PreviousName {
+		return m.previous(workspaces)
+	}
+	return resolve(workspaces, name)
+}
+
+// resolve finds the workspace name refers to among workspaces.
+func resolve(workspaces []Workspace, name string) (*Workspace, error) {
+	for i := range workspaces {
+		if workspaces[i].Name == name {
+			return &workspaces[i], nil
+		}
+	}
+
+	var prefixed, fuzzy []Workspace
+	scores := make(map[string]int)
+	if name != "" {
+		for _, ws := range workspaces {
+			if strings.HasPrefix(ws.Name, name) {
+				prefixed = append(prefixed, ws)
+			}
+			if score, ok := FuzzyScore(name, ws.Name); ok {
+				fuzzy = append(fuzzy, ws)
+				scores[ws.Name] = score
+			}
+		}
+	}
+	sort.SliceStable(fuzzy, func(i, j int) bool {
+		return scores[fuzzy[i].Name] > scores[fuzzy[j].Name]
+	})
+
+	for _, matches := range [][]Workspace{prefixed, fuzzy} {
+		switch {
+		case len(matches) == 1:
+			return &matches[0], nil
+		case len(matches) > 1:
+			return nil, &AmbiguousError{Name: name, Candidates: names(matches)}
+		}
+	}
+	return nil, &NotFoundError{Name: name, Available: names(workspaces)}
+}
+
+func names(workspaces []Workspace) []string {
+	var names []string
+	for _, ws := range workspaces {
+		names = append(names, ws.Name)
+	}
+	return names
+}
+
+// visitsPath returns where the workspaces visited last are recorded, most
+// recent first.
+func (m *Manager) visitsPath() (string, error) {
+	dir, err := m.stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "visited"), nil
+}
+
+// visits returns the workspaces visited last, most recent first.
+func (m *Manager) visits() []string {
+	path, err := m.visitsPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(data))
+}
+
+// RecordVisit notes that the user went to the named workspace, so that
+// PreviousName can lead back to it.
+func (m *Manager) RecordVisit(name string) error {
+	visits := m.visits()
+	if len(visits) > 0 && visits[0] == name {
+		return nil
+	}
+	// The last two visits are enough to tell the previous workspace from
+	// the current one
+	visits = append([]string{name}, visits...)
+	visits = visits[:min(len(visits), 2)]
+
+	path, err := m.visitsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, []byte(strings.Join(visits, "\n")+"\n"), 0644)
+}
+
+// previous returns the most recently visited workspace other than the
+// one the working directory is in.
+func (m *Manager) previous(workspaces []Workspace) (*Workspace, error) {
+	cwd, _ := os.Getwd()
+	for _, name := range m.visits() {
+		for i := range workspaces {
+			ws := &workspaces[i]
+			if ws.Name == name && !isInDirectory(cwd, ws.Path) {
+				return ws, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no previous workspace")
+}
@@ -0,0 +1,96 @@
+package workspace
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	workspaces := []Workspace{
+		{Name: "fix-auth-token-refresh-race"},
+		{Name: "auth-cleanup"},
+		{Name: "a1"},
+		{Name: "a10"},
+		{Name: "zeta-branch"},
+	}
+
+	tests := []struct {
+		name       string
+		want       string   // Workspace resolved to
+		candidates []string // Else, the candidates of an AmbiguousError
+		notFound   bool
+	}{
+		{name: "a1", want: "a1"}, // Exact beats prefix
+		{name: "auth", want: "auth-cleanup"},
+		{name: "fix", want: "fix-auth-token-refresh-race"},
+		{name: "zeta", want: "zeta-branch"},
+		{name: "atr", want: "fix-auth-token-refresh-race"},
+		{name: "ZB", want: "zeta-branch"},
+		{name: "a", candidates: []string{"auth-cleanup", "a1", "a10"}},
+		{name: "ah", candidates: []string{"auth-cleanup", "fix-auth-token-refresh-race", "zeta-branch"}},
+		{name: "xyz", notFound: true},
+		{name: "", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, err := resolve(workspaces, tt.name)
+
+			var ambiguous *AmbiguousError
+			var notFound *NotFoundError
+			switch {
+			case tt.want != "":
+				if err != nil {
+					t.Fatalf("resolve(%q) failed: %v", tt.name, err)
+				}
+				if ws.Name != tt.want {
+					t.Errorf("resolve(%q) = %s, want %s", tt.name, ws.Name, tt.want)
+				}
+			case tt.candidates != nil:
+				if !errors.As(err, &ambiguous) {
+					t.Fatalf("resolve(%q) = %v, %v; want ambiguous", tt.name, ws, err)
+				}
+				if !reflect.DeepEqual(ambiguous.Candidates, tt.candidates) {
+					t.Errorf("resolve(%q) candidates = %v, want %v", tt.name, ambiguous.Candidates, tt.candidates)
+				}
+			case tt.notFound:
+				if !errors.As(err, &notFound) {
+					t.Fatalf("resolve(%q) = %v, %v; want not found", tt.name, ws, err)
+				}
+				if len(notFound.Available) != len(workspaces) {
+					t.Errorf("resolve(%q) lists %d available, want %d", tt.name, len(notFound.Available), len(workspaces))
+				}
+			}
+		})
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		ok         bool
+	}{
+		{"auth", "auth-fix", true},
+		{"AUTH", "auth-fix", true},
+		{"afx", "auth-fix", true},
+		{"xa", "auth-fix", false},
+		{"", "anything", true},
+		{"é", "café", true},
+	}
+	for _, tt := range tests {
+		if _, ok := FuzzyScore(tt.pattern, tt.s); ok != tt.ok {
+			t.Errorf("FuzzyScore(%q, %q) matched = %v, want %v", tt.pattern, tt.s, ok, tt.ok)
+		}
+	}
+
+	// Better matches score higher
+	ranked := []string{"auth-fix", "oauth", "a-u-t-h"}
+	for i := 1; i < len(ranked); i++ {
+		better, _ := FuzzyScore("auth", ranked[i-1])
+		worse, _ := FuzzyScore("auth", ranked[i])
+		if better <= worse {
+			t.Errorf("FuzzyScore(auth): %s scored %d, not above %s at %d", ranked[i-1], better, ranked[i], worse)
+		}
+	}
+}
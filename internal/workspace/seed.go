@@ -0,0 +1,149 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/WillCMcC/ws/internal/config"
+)
+
+// errReflinkUnsupported is returned by cloneFile on platforms without
+// copy-on-write file cloning.
+var errReflinkUnsupported = errors.New("reflinks not supported")
+
+// seedStats tallies how the files of a seeded directory were populated.
+type seedStats struct {
+	files  int
+	cloned int64 // Bytes shared via reflink
+	linked int64 // Bytes shared via hardlink
+	copied int64 // Bytes duplicated
+}
+
+// saved returns the bytes that didn't have to be duplicated on disk.
+func (s seedStats) saved() int64 {
+	return s.cloned + s.linked
+}
+
+// seedDirs populates the configured dependency directories (node_modules,
+// .venv, ...) in a new workspace from the main worktree, so installs in
+// the workspace start warm. Files are cloned with reflinks where the
+// filesystem supports it, otherwise seed_fallback decides. Failures are
+// reported as warnings.
+func (m *Manager) seedDirs(wsPath string) {
+	var total seedStats
+	for _, dir := range m.Config.Workspace.SeedDirs {
+		src := filepath.Join(m.RepoRoot, dir)
+		dst := filepath.Join(wsPath, dir)
+
+		if info, err := os.Stat(src); err != nil || !info.IsDir() {
+			continue
+		}
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+
+		stats, err := seedDir(src, dst, m.Config.Workspace.SeedFallback)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to seed %s: %v\n", dir, err)
+			os.RemoveAll(dst)
+			continue
+		}
+		fmt.Printf("Seeded %s: %d file(s), %s\n", dir, stats.files, formatBytes(stats.cloned+stats.linked+stats.copied))
+
+		total.files += stats.files
+		total.cloned += stats.cloned
+		total.linked += stats.linked
+		total.copied += stats.copied
+	}
+
+	if total.saved() > 0 {
+		fmt.Printf("Saved %s of disk by sharing seeded files\n", formatBytes(total.saved()))
+	}
+}
+
+// seedDir recreates the tree at src under dst. Each regular file is
+// reflinked; after the first failed clone the rest of the tree uses the
+// fallback mode instead.
+func seedDir(src, dst, fallback string) (seedStats, error) {
+	var stats seedStats
+	reflink := true
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			// Skip sockets, devices and other special files
+			return nil
+		}
+
+		stats.files++
+		size := info.Size()
+
+		if reflink {
+			err := cloneFile(path, target)
+			if err == nil {
+				stats.cloned += size
+				return nil
+			}
+			// Clean up a partially created clone before falling back
+			os.Remove(target)
+			reflink = false
+		}
+
+		switch fallback {
+		case config.SeedNone:
+			return fmt.Errorf("reflinks unsupported and seed_fallback is none")
+		case config.SeedHardlink:
+			if err := os.Link(path, target); err == nil {
+				stats.linked += size
+				return nil
+			}
+			// Across filesystems hardlinks fail too; copy instead
+		}
+
+		if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+			return err
+		}
+		stats.copied += size
+		return nil
+	})
+	return stats, err
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 GiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,11 @@
+//go:build darwin
+
+package workspace
+
+import "golang.org/x/sys/unix"
+
+// cloneFile creates dst as a copy-on-write clone of src using clonefile(2)
+// (APFS).
+func cloneFile(src, dst string) error {
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package workspace
+
+// cloneFile always fails where copy-on-write cloning isn't implemented,
+// so seeding uses the configured fallback.
+func cloneFile(src, dst string) error {
+	return errReflinkUnsupported
+}
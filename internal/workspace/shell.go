@@ -0,0 +1,37 @@
+package workspace
+
+import "strings"
+
+// ShellQuote quotes s as a single word for sh, bash, zsh and fish alike:
+// single quotes everywhere except for quote and backslash characters,
+// which fish treats specially inside them and which go in double quotes.
+func ShellQuote(s string) string {
+	var b strings.Builder
+	open := false
+	for _, r := range s {
+		if r == '\'' || r == '\\' {
+			if open {
+				b.WriteByte('\'')
+				open = false
+			}
+			if r == '\'' {
+				b.WriteString(`"'"`)
+			} else {
+				b.WriteString(`"\\"`)
+			}
+			continue
+		}
+		if !open {
+			b.WriteByte('\'')
+			open = true
+		}
+		b.WriteRune(r)
+	}
+	if open {
+		b.WriteByte('\'')
+	}
+	if b.Len() == 0 {
+		return "''"
+	}
+	return b.String()
+}
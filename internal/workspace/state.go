@@ -0,0 +1,233 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/git"
+	"github.com/WillCMcC/ws/internal/process"
+)
+
+// State is what is happening in a workspace, as far as ws can tell from
+// its processes and worktree.
+type State string
+
+const (
+	StateRunning      State = "running"           // An agent is working
+	StateWaiting      State = "waiting-for-input" // An agent is running but quiet
+	StateIdle         State = "idle"              // No agent and nothing uncommitted
+	StateExited       State = "exited"            // An agent ws started has finished, with nothing committed since
+	StateConflicted   State = "conflicted"        // Unmerged paths, from a rebase or merge stopped on conflicts
+	StateDirtyNoAgent State = "dirty-no-agent"    // Uncommitted changes and no agent
+
+	// StateUnknown is reported when the worktree's status can't be read in
+	// time. It isn't one of States and can't be filtered on.
+	StateUnknown State = "unknown"
+)
+
+// States lists every state.
+var States = []State{StateRunning, StateWaiting, StateIdle, StateExited, StateConflicted, StateDirtyNoAgent}
+
+// ParseState parses a state name.
+func ParseState(s string) (State, error) {
+	for _, state := range States {
+		if string(state) == s {
+			return state, nil
+		}
+	}
+	names := make([]string, len(States))
+	for i, state := range States {
+		names[i] = string(state)
+	}
+	return "", fmt.Errorf("unknown state '%s' (expected one of %s)", s, strings.Join(names, ", "))
+}
+
+const (
+	// sampleInterval is how far apart the two process table snapshots
+	// used to measure agent CPU use are taken.
+	sampleInterval = 400 * time.Millisecond
+	// busyCPU is the CPU time an agent and its children must use between
+	// the snapshots to count as running.
+	busyCPU = 20 * time.Millisecond
+	// recentWrite is how recently a changed file must have been written
+	// for an otherwise quiet agent to count as running; agents waiting on
+	// their model use no CPU.
+	recentWrite = 2 * time.Minute
+)
+
+// StateClassifier classifies workspaces. It snapshots the process table
+// when created and takes a second snapshot the first time an agent's CPU
+// use needs measuring, so classifying every workspace costs at most one
+// sampling delay. It is safe for concurrent use.
+type StateClassifier struct {
+	m       *Manager
+	matcher *process.Matcher // nil when process detection is off
+	first   *process.Table
+	taken   time.Time
+
+	sampleOnce sync.Once
+	second     *process.Table // nil if the second snapshot failed
+}
+
+// NewStateClassifier snapshots the process table for classifying
+// workspaces. With process detection disabled, or if the table can't be
+// read, workspaces are classified from git state alone.
+func (m *Manager) NewStateClassifier() *StateClassifier {
+	c := &StateClassifier{m: m}
+	if !m.Config.Status.DetectProcesses {
+		return c
+	}
+
+	matcher, err := m.AgentMatcher()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return c
+	}
+	table, err := process.Snapshot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read process table: %v\n", err)
+		return c
+	}
+	c.matcher, c.first, c.taken = matcher, table, time.Now()
+	return c
+}
+
+// AgentMatcher returns the matcher for the agent processes configured in
+// agent_processes, agent_cmdline_patterns and agent_exe_paths.
+func (m *Manager) AgentMatcher() (*process.Matcher, error) {
+	status := m.Config.Status
+	return process.NewMatcher(status.AgentProcesses, status.AgentPatterns, status.AgentExePaths)
+}
+
+// Detecting reports whether agents are being detected.
+func (c *StateClassifier) Detecting() bool {
+	return c.first != nil
+}
+
+// Agents returns the agents running in ws.
+func (c *StateClassifier) Agents(ws Workspace) []process.AgentProcess {
+	if c.first == nil {
+		return nil
+	}
+	return c.first.Agents(ws.Path, c.matcher)
+}
+
+// classify returns the state of ws given its porcelain status and last
+// commit. Only unmerged paths make it conflicted: a rebase or cherry-pick
+// stopped cleanly, at an edit say, is classified like any other worktree.
+func (c *StateClassifier) classify(ws Workspace, status string, lastCommit Commit) State {
+	if git.HasUnmergedPaths(status) {
+		return StateConflicted
+	}
+
+	if agents := c.Agents(ws); len(agents) > 0 {
+		switch {
+		case c.cpuUsed(ws) >= busyCPU:
+			return StateRunning
+		case c.readingTTY(agents):
+			return StateWaiting
+		case recentlyWritten(ws.Path, status):
+			return StateRunning
+		}
+		return StateWaiting
+	}
+
+	if c.Detecting() && agentExited(ws.Meta.LastAgent, lastCommit) {
+		return StateExited
+	}
+	if status != "" {
+		return StateDirtyNoAgent
+	}
+	return StateIdle
+}
+
+// cpuUsed returns the CPU time the agents in ws and their children used
+// between the two snapshots. Processes started in between count in full.
+func (c *StateClassifier) cpuUsed(ws Workspace) time.Duration {
+	c.sampleOnce.Do(func() {
+		time.Sleep(time.Until(c.taken.Add(sampleInterval)))
+		c.second, _ = process.Snapshot()
+	})
+	if c.second == nil {
+		return 0
+	}
+
+	var used time.Duration
+	for _, agent := range c.second.Agents(ws.Path, c.matcher) {
+		for _, pid := range append([]int{agent.PID}, agent.Children...) {
+			after, ok := c.second.Get(pid)
+			if !ok {
+				continue
+			}
+			before, _ := c.first.Get(pid)
+			used += after.CPUTime - before.CPUTime
+		}
+	}
+	return used
+}
+
+// readingTTY reports whether any of the agents, or the processes they
+// started, are blocked reading the terminal.
+func (c *StateClassifier) readingTTY(agents []process.AgentProcess) bool {
+	for _, agent := range agents {
+		for _, pid := range append([]int{agent.PID}, agent.Children...) {
+			if p, ok := c.first.Get(pid); ok && p.ReadingTTY {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recentlyWritten reports whether any file listed in the porcelain status
+// output was modified within recentWrite.
+func recentlyWritten(wsPath, status string) bool {
+	cutoff := time.Now().Add(-recentWrite)
+	for _, line := range strings.Split(status, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		path := line[3:]
+		// Renames are shown as "old -> new"
+		if _, after, ok := strings.Cut(path, " -> "); ok {
+			path = after
+		}
+		info, err := os.Stat(filepath.Join(wsPath, strings.Trim(path, "\"")))
+		if err == nil && info.ModTime().After(cutoff) {
+			return true
+		}
+	}
+	return false
+}
+
+// stateDir returns the directory ws keeps its own state in, inside the
+// repository's shared git directory.
+func (m *Manager) stateDir() (string, error) {
+	m.stateDirOnce.Do(func() {
+		commonDir, err := git.GetCommonDir(m.RepoRoot)
+		if err != nil {
+			m.stateDirErr = err
+			return
+		}
+		m.stateDirPath = filepath.Join(commonDir, "ws")
+	})
+	return m.stateDirPath, m.stateDirErr
+}
+
+// agentExited reports whether the agent ws last started has finished
+// with nothing committed since, so its work is waiting to be looked at.
+// When ws didn't see the agent exit, its start time stands in.
+func agentExited(run *AgentRun, lastCommit Commit) bool {
+	if run == nil {
+		return false
+	}
+	since := run.Exited
+	if since.IsZero() {
+		since = run.Time
+	}
+	return lastCommit.IsZero() || !lastCommit.Time.After(since)
+}
@@ -0,0 +1,66 @@
+package workspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WillCMcC/ws/internal/process"
+)
+
+func TestClassify(t *testing.T) {
+	started := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	before := Commit{SHA: "a", Time: started.Add(-time.Hour)}
+	after := Commit{SHA: "b", Time: started.Add(time.Hour)}
+
+	tests := []struct {
+		name       string
+		status     string
+		lastAgent  *AgentRun
+		lastCommit Commit
+		want       State
+	}{
+		{"clean", "", nil, before, StateIdle},
+		{"dirty", " M main.go\n", nil, before, StateDirtyNoAgent},
+		{"unmerged", "UU main.go\n", nil, before, StateConflicted},
+		{"unmerged among others", "M  go.mod\nAA new.go\n", nil, before, StateConflicted},
+		// A rebase stopped at an edit, with the edit staged
+		{"staged", "M  main.go\n", nil, before, StateDirtyNoAgent},
+
+		{"agent finished", " M main.go\n", &AgentRun{Name: "claude", Time: started}, before, StateExited},
+		{"agent finished, no commits", "", &AgentRun{Name: "claude", Time: started}, Commit{}, StateExited},
+		{"committed since start", "", &AgentRun{Name: "claude", Time: started}, after, StateIdle},
+		{"committed before exit", "", &AgentRun{Name: "claude", Time: started, Exited: started.Add(2 * time.Hour)}, after, StateExited},
+		{"committed since exit", " M main.go\n", &AgentRun{Name: "claude", Time: started, Exited: started.Add(time.Minute)}, after, StateDirtyNoAgent},
+	}
+
+	// An empty process table: agents are detected, and none are running
+	c := &StateClassifier{first: process.NewTable(nil), matcher: &process.Matcher{}}
+	for _, tt := range tests {
+		ws := Workspace{Name: "ws", Path: t.TempDir(), Meta: Meta{LastAgent: tt.lastAgent}}
+		if got := c.classify(ws, tt.status, tt.lastCommit); got != tt.want {
+			t.Errorf("%s: classify(%q) = %s, want %s", tt.name, tt.status, got, tt.want)
+		}
+	}
+
+	// Without process detection, a finished agent can't be told apart
+	// from one still running
+	ws := Workspace{Name: "ws", Meta: Meta{LastAgent: &AgentRun{Name: "claude", Time: started}}}
+	if got := (&StateClassifier{}).classify(ws, "", before); got != StateIdle {
+		t.Errorf("classify without detection = %s, want %s", got, StateIdle)
+	}
+}
+
+func TestAgentName(t *testing.T) {
+	tests := []struct {
+		command, want string
+	}{
+		{"claude --dangerously-skip-permissions", "claude"},
+		{"/usr/local/bin/aider --yes 'fix it'", "aider"},
+		{"FORCE_COLOR=1 codex", "codex"},
+	}
+	for _, tt := range tests {
+		if got := agentName(tt.command); got != tt.want {
+			t.Errorf("agentName(%q) = %s, want %s", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package workspace
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WillCMcC/ws/internal/config"
+	"github.com/WillCMcC/ws/internal/fsutil"
+)
+
+// Trustable is something a repository provides that only runs once the
+// user approves it: a hook, or an agent command set in its .ws/config.
+type Trustable interface {
+	// Content returns what will run. Approvals are keyed on it.
+	Content() []byte
+}
+
+// TrustStore records which repository-provided hooks and agent commands
+// the user has approved. Entries are keyed by repository path and the
+// SHA-256 of the content, so editing one requires approving it again.
+//
+// The store is a text file with one "<sha256> <repo path>" entry per line.
+type TrustStore struct {
+	path    string
+	entries map[trustEntry]bool
+}
+
+type trustEntry struct {
+	hash     string
+	repoRoot string
+}
+
+// TrustStorePath returns the location of the trust store.
+func TrustStorePath() string {
+	return filepath.Join(filepath.Dir(config.GlobalPath()), "trusted")
+}
+
+// LoadTrustStore reads the trust store. A missing store is empty.
+func LoadTrustStore() (*TrustStore, error) {
+	t := &TrustStore{
+		path:    TrustStorePath(),
+		entries: make(map[trustEntry]bool),
+	}
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return t, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", 2)
+		if len(parts) == 2 {
+			t.entries[trustEntry{hash: parts[0], repoRoot: parts[1]}] = true
+		}
+	}
+	return t, scanner.Err()
+}
+
+// Trusted reports whether item has been approved for repoRoot.
+func (t *TrustStore) Trusted(repoRoot string, item Trustable) bool {
+	return t.entries[trustEntry{hash: hashContent(item), repoRoot: repoRoot}]
+}
+
+// Trust approves item for repoRoot.
+func (t *TrustStore) Trust(repoRoot string, item Trustable) {
+	t.entries[trustEntry{hash: hashContent(item), repoRoot: repoRoot}] = true
+}
+
+// Revoke removes every approval for repoRoot and returns how many there were.
+func (t *TrustStore) Revoke(repoRoot string) int {
+	count := 0
+	for entry := range t.entries {
+		if entry.repoRoot == repoRoot {
+			delete(t.entries, entry)
+			count++
+		}
+	}
+	return count
+}
+
+// Save atomically writes the trust store back to disk.
+func (t *TrustStore) Save() error {
+	lines := make([]string, 0, len(t.entries))
+	for entry := range t.entries {
+		lines = append(lines, entry.hash+" "+entry.repoRoot)
+	}
+	sort.Strings(lines)
+
+	data := strings.Join(lines, "\n")
+	if data != "" {
+		data += "\n"
+	}
+	return fsutil.WriteFileAtomic(t.path, []byte(data), 0600)
+}
+
+// hashContent returns the hex SHA-256 of an item's content.
+func hashContent(item Trustable) string {
+	sum := sha256.Sum256(item.Content())
+	return hex.EncodeToString(sum[:])
+}
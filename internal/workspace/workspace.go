@@ -1,9 +1,12 @@
 package workspace
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/WillCMcC/ws/internal/config"
@@ -12,16 +15,23 @@ import (
 
 // Workspace represents a managed workspace.
 type Workspace struct {
-	Name     string
-	Path     string
-	Branch   string
-	Modified time.Time
+	Name       string
+	Path       string
+	Branch     string
+	Modified   time.Time
+	Locked     bool
+	LockReason string
+	Meta       Meta // What ws recorded about the workspace
 }
 
 // Manager handles workspace operations.
 type Manager struct {
 	RepoRoot string
 	Config   *config.Config
+
+	stateDirOnce sync.Once // Guards the state directory, cached by stateDir
+	stateDirPath string
+	stateDirErr  error
 }
 
 // NewManager creates a new workspace manager.
@@ -33,7 +43,7 @@ func NewManager() (*Manager, error) {
 
 	return &Manager{
 		RepoRoot: repoRoot,
-		Config:   config.Load(),
+		Config:   config.Load(repoRoot),
 	}, nil
 }
 
@@ -57,6 +67,15 @@ func (m *Manager) Create(name, base string, noHooks bool) error {
 		return fmt.Errorf("directory '%s' already exists", wsPath)
 	}
 
+	hc := HookContext{Name: name, Path: wsPath, Branch: name, Base: base}
+
+	// Run pre-create hook in the main repo before anything is created
+	if !noHooks {
+		if err := m.runHook(EventPreCreate, hc); err != nil {
+			return err
+		}
+	}
+
 	// Ensure workspace directory exists
 	if err := os.MkdirAll(wsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create workspace directory: %w", err)
@@ -66,11 +85,18 @@ func (m *Manager) Create(name, base string, noHooks bool) error {
 	if err := git.CreateWorktree(wsPath, name, base); err != nil {
 		return fmt.Errorf("failed to create worktree: %w", err)
 	}
+	m.recordCreate(name, wsPath, base)
+
+	// Bring over untracked local files (.env etc.) and dependency
+	// directories before hooks need them
+	m.copyLocalFiles(wsPath)
+	m.seedDirs(wsPath)
 
 	// Run post-create hook if configured and not disabled
-	if !noHooks && m.Config.Hooks.PostCreate != "" {
-		if err := runHook(m.Config.Hooks.PostCreate, wsPath, name); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: post-create hook failed: %v\n", err)
+	if !noHooks {
+		if err := m.runHook(EventPostCreate, hc); err != nil {
+			m.rollbackCreate(name, wsPath)
+			return err
 		}
 	}
 
@@ -97,15 +123,22 @@ func (m *Manager) List() ([]Workspace, error) {
 		}
 
 		ws := Workspace{
-			Name:   filepath.Base(wt.Path),
-			Path:   wt.Path,
-			Branch: wt.Branch,
+			Name:       filepath.Base(wt.Path),
+			Path:       wt.Path,
+			Branch:     wt.Branch,
+			Locked:     wt.Locked,
+			LockReason: wt.LockReason,
 		}
 
 		// Get modification time
 		if info, err := os.Stat(wt.Path); err == nil {
 			ws.Modified = info.ModTime()
 		}
+		if meta, err := m.LoadMeta(ws.Name); err == nil {
+			ws.Meta = meta
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 
 		workspaces = append(workspaces, ws)
 	}
@@ -113,24 +146,21 @@ func (m *Manager) List() ([]Workspace, error) {
 	return workspaces, nil
 }
 
-// Get returns a workspace by name.
-func (m *Manager) Get(name string) (*Workspace, error) {
-	workspaces, err := m.List()
-	if err != nil {
-		return nil, err
-	}
-
-	for _, ws := range workspaces {
-		if ws.Name == name {
-			return &ws, nil
-		}
-	}
-
-	return nil, fmt.Errorf("workspace '%s' not found", name)
+// RemoveOptions controls how Remove treats a workspace that isn't ready
+// to be removed.
+type RemoveOptions struct {
+	Force         bool // Remove despite uncommitted changes; stops running processes
+	KeepBranch    bool // Don't delete the branch
+	StopProcesses bool // Stop processes running in the workspace rather than refusing
 }
 
-// Remove removes a workspace.
-func (m *Manager) Remove(name string, force, keepBranch bool) error {
+// Remove removes a workspace. It refuses while the workspace has
+// uncommitted changes or processes running in it, unless opts say
+// otherwise; running processes are stopped rather than having their
+// directory deleted out from under them.
+func (m *Manager) Remove(name string, opts RemoveOptions) error {
+	force, keepBranch := opts.Force, opts.KeepBranch
+
 	ws, err := m.Get(name)
 	if err != nil {
 		return err
@@ -151,13 +181,32 @@ func (m *Manager) Remove(name string, force, keepBranch bool) error {
 		return fmt.Errorf("workspace has uncommitted changes")
 	}
 
-	// Run pre-remove hook if configured
-	if m.Config.Hooks.PreRemove != "" {
-		if err := runHook(m.Config.Hooks.PreRemove, ws.Path, name); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: pre-remove hook failed: %v\n", err)
+	procs, err := RunningProcesses(ws.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if len(procs) > 0 {
+		if !force && !opts.StopProcesses {
+			fmt.Printf("Workspace %s has %d process(es) running:\n", name, len(procs))
+			printProcesses(procs)
+			fmt.Println()
+			fmt.Printf("ws done --stop-agents %s  # stop them, then remove\n", name)
+			fmt.Printf("ws kill %s                # or just stop them\n", name)
+			return fmt.Errorf("workspace has running processes")
+		}
+		if err := StopProcesses(procs, syscall.SIGTERM, StopGrace); err != nil {
+			return fmt.Errorf("failed to stop processes: %w", err)
 		}
 	}
 
+	// Remove hooks see the base the workspace was created from
+	hc := HookContext{Name: name, Path: ws.Path, Branch: cmp.Or(ws.Branch, name), Base: cmp.Or(ws.Meta.BaseRef, m.Config.GetDefaultBase())}
+
+	// Run pre-remove hook if configured
+	if err := m.runHook(EventPreRemove, hc); err != nil {
+		return err
+	}
+
 	// Remove worktree
 	if err := git.RemoveWorktree(ws.Path, force); err != nil {
 		return fmt.Errorf("failed to remove worktree: %w", err)
@@ -170,12 +219,51 @@ func (m *Manager) Remove(name string, force, keepBranch bool) error {
 		}
 	}
 
+	m.forgetMeta(name)
+
 	fmt.Printf("Removed workspace: %s\n", name)
 	if !keepBranch {
 		fmt.Printf("  Branch %s deleted\n", name)
 	}
 
-	return nil
+	// Run post-remove hook from the main repo now the workspace is gone
+	return m.runHook(EventPostRemove, hc)
+}
+
+// rollbackCreate removes a worktree and branch created by Create after a
+// later step aborted.
+func (m *Manager) rollbackCreate(name, wsPath string) {
+	m.forgetMeta(name)
+	if err := git.RemoveWorktree(wsPath, true); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to roll back worktree %s: %v\n", wsPath, err)
+		return
+	}
+	if err := git.DeleteBranch(name, true); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to roll back branch %s: %v\n", name, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Rolled back workspace: %s\n", name)
+}
+
+// Current returns the workspace containing the current directory.
+func (m *Manager) Current() (*Workspace, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	workspaces, err := m.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	for _, ws := range workspaces {
+		if isInDirectory(cwd, ws.Path) {
+			return &ws, nil
+		}
+	}
+
+	return nil, fmt.Errorf("not in a workspace")
 }
 
 // GetPath returns the path to a workspace.
@@ -187,6 +275,53 @@ func (m *Manager) GetPath(name string) (string, error) {
 	return ws.Path, nil
 }
 
+// Subdir returns the directory in the worktree at root that corresponds
+// to where the working directory is in its own worktree, so that moving
+// between worktrees keeps you in, say, services/api. A directory missing
+// from root falls back to its deepest existing ancestor, and a working
+// directory outside any worktree to root itself.
+func (m *Manager) Subdir(root string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return root
+	}
+	// Worktree paths are real paths; the working directory may not be
+	cwd, err = filepath.EvalSymlinks(cwd)
+	if err != nil {
+		return root
+	}
+
+	worktrees, err := git.ListWorktrees()
+	if err != nil {
+		return root
+	}
+	// Workspaces can live inside the main worktree, so take the deepest
+	source := ""
+	for _, wt := range worktrees {
+		path, err := filepath.EvalSymlinks(wt.Path)
+		if err != nil {
+			continue
+		}
+		if isInDirectory(cwd, path) && len(path) > len(source) {
+			source = path
+		}
+	}
+	if source == "" {
+		return root
+	}
+
+	rel, err := filepath.Rel(source, cwd)
+	if err != nil {
+		return root
+	}
+	for dir := filepath.Join(root, rel); isInDirectory(dir, root); dir = filepath.Dir(dir) {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return root
+}
+
 // GetMainWorktree returns info about the main worktree.
 func (m *Manager) GetMainWorktree() (string, string, error) {
 	branch, err := git.GetCurrentBranch()
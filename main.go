@@ -25,6 +25,8 @@ func main() {
 		exitCode = cmd.NewCmd(args)
 	case "ez":
 		exitCode = cmd.EzCmd(args)
+	case "agent":
+		exitCode = cmd.AgentCmd(args)
 	case "list", "ls":
 		exitCode = cmd.ListCmd(args)
 	case "go":
@@ -39,12 +41,27 @@ func main() {
 		exitCode = cmd.AutoRebaseCmd(args)
 	case "status", "st":
 		exitCode = cmd.StatusCmd(args)
+	case "ps":
+		exitCode = cmd.PsCmd(args)
+	case "kill":
+		exitCode = cmd.KillCmd(args)
+	case "note":
+		exitCode = cmd.NoteCmd(args)
+	case "ui":
+		exitCode = cmd.UICmd(args)
+	case "wait":
+		exitCode = cmd.WaitCmd(args)
 	case "prune":
 		exitCode = cmd.PruneCmd(args)
 	case "init":
 		exitCode = cmd.InitCmd(args)
 	case "config":
 		exitCode = cmd.ConfigCmd(args)
+	case "trust":
+		exitCode = cmd.TrustCmd(args)
+	case "hook":
+		// Internal command for shell integration to fire post-agent hooks
+		exitCode = cmd.HookCmd(args)
 	case "agent-cmd":
 		// Internal command for shell integration to get agent command
 		fmt.Print(cmd.GetAgentCmd())
@@ -72,16 +89,23 @@ Usage: ws <command> [arguments]
 Commands:
   new <name>     Create a new workspace and navigate to it
   ez <name>      Create workspace, navigate, and start agent
+  agent [name]   Start agent in a workspace
   list           List all workspaces
-  go <name>      Navigate to a workspace
+  go [name]      Navigate to a workspace
   home           Navigate to main repository
-  done <name>    Remove a workspace
+  done [name]    Remove a workspace
   fold [name]    Rebase and merge workspace into default branch
   auto-rebase    Start agent to help resolve rebase conflicts
   status         Show detailed status of all workspaces
+  ps [name]      List processes running inside workspaces
+  kill <name>    Stop the processes running inside a workspace
+  note <name>    Attach a note to a workspace, or list its notes
+  ui             Open a live dashboard of all workspaces
+  wait <name>    Block until a workspace reaches a condition
   prune          Clean up stale worktrees
   init           Set up shell integration
   config         Manage configuration
+  trust          Approve hooks provided by the repository
 
 Aliases:
   ls             Alias for 'list'
@@ -2,24 +2,23 @@
 # Add to ~/.bashrc: source /path/to/ws.bash
 
 ws() {
-    if [[ "$1" == "go" && -n "$2" ]]; then
+    if [[ "$1" == "go" ]]; then
+        # Errors, and the picker when no name is given, go to the terminal
         local target
-        target=$(command ws go "$2" 2>/dev/null)
+        target=$(command ws "$@")
         local exit_code=$?
         if [[ $exit_code -eq 0 && -n "$target" && -d "$target" ]]; then
             cd "$target" || return 1
-        else
-            command ws go "$2"
-            return $exit_code
         fi
+        return $exit_code
     elif [[ "$1" == "home" ]]; then
         local target
-        target=$(command ws home 2>/dev/null)
+        target=$(command ws "$@" 2>/dev/null)
         local exit_code=$?
         if [[ $exit_code -eq 0 && -n "$target" && -d "$target" ]]; then
             cd "$target" || return 1
         else
-            command ws home
+            command ws "$@"
             return $exit_code
         fi
     elif [[ "$1" == "new" && -n "$2" ]]; then
@@ -33,20 +32,43 @@ ws() {
             fi
         fi
         return $exit_code
-    elif [[ "$1" == "ez" && -n "$2" ]]; then
-        command ws "$@"
+    elif [[ "$1" == "ez" || "$1" == "agent" || "$1" == "ui" ]]; then
+        # ws writes the workspace to cd into, then any agent command to run
+        local handoff
+        handoff=$(mktemp "${TMPDIR:-/tmp}/ws.XXXXXX") || return 1
+        WS_HANDOFF="$handoff" command ws "$@"
         local exit_code=$?
-        if [[ $exit_code -eq 0 ]]; then
-            local target
-            target=$(command ws go "$2" 2>/dev/null)
-            if [[ -n "$target" && -d "$target" ]]; then
-                cd "$target" || return 1
-                local agent_cmd
-                agent_cmd=$(command ws agent-cmd)
+        local target agent_cmd
+        { IFS= read -r target; agent_cmd=$(cat); } < "$handoff"
+        rm -f "$handoff"
+        if [[ -n "$target" && -d "$target" ]]; then
+            cd "$target" || return 1
+            if [[ $exit_code -eq 0 && -n "$agent_cmd" ]]; then
                 eval "$agent_cmd"
+                command ws hook post-agent
             fi
         fi
         return $exit_code
+    elif [[ "$1" == "fold" ]]; then
+        command ws "$@"
+        local exit_code=$?
+        # After fold, go home (workspace may be deleted)
+        local target
+        target=$(command ws home 2>/dev/null)
+        if [[ -n "$target" && -d "$target" ]]; then
+            cd "$target" || return 1
+        fi
+        return $exit_code
+    elif [[ "$1" == "auto-rebase" ]]; then
+        command ws "$@"
+        local exit_code=$?
+        if [[ $exit_code -eq 0 ]]; then
+            local agent_cmd
+            agent_cmd=$(command ws agent-cmd)
+            eval "$agent_cmd" "Help me finish this rebase. There are merge conflicts that need to be resolved. Look at the conflicted files, understand both sides, and resolve them appropriately. Ask me any questions if you're unsure about the intent. Once resolved, run 'git add' on the fixed files and 'git rebase --continue'."
+            command ws hook post-agent
+        fi
+        return $exit_code
     else
         command ws "$@"
     fi
@@ -55,10 +77,10 @@ ws() {
 # Optional: completion
 _ws_completions() {
     if [[ ${COMP_CWORD} -eq 1 ]]; then
-        COMPREPLY=($(compgen -W "new ez list go home done fold status prune init" -- "${COMP_WORDS[1]}"))
+        COMPREPLY=($(compgen -W "new ez agent list go home done fold auto-rebase status ps kill note ui wait prune init config" -- "${COMP_WORDS[1]}"))
     elif [[ ${COMP_CWORD} -eq 2 ]]; then
         case "${COMP_WORDS[1]}" in
-            go|done|fold|status)
+            go|done|fold|status|ps|kill|note|wait|agent)
                 local workspaces
                 workspaces=$(command ws list --quiet 2>/dev/null)
                 COMPREPLY=($(compgen -W "$workspaces" -- "${COMP_WORDS[2]}"))
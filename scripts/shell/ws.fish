@@ -2,22 +2,21 @@
 # Add to ~/.config/fish/conf.d/ws.fish
 
 function ws
-    if test "$argv[1]" = "go" -a -n "$argv[2]"
-        set -l target (command ws go $argv[2] 2>/dev/null)
+    if test "$argv[1]" = "go"
+        # Errors, and the picker when no name is given, go to the terminal
+        set -l target (command ws $argv)
         set -l exit_code $status
         if test $exit_code -eq 0 -a -n "$target" -a -d "$target"
             cd $target
-        else
-            command ws go $argv[2]
-            return $exit_code
         end
+        return $exit_code
     else if test "$argv[1]" = "home"
-        set -l target (command ws home 2>/dev/null)
+        set -l target (command ws $argv 2>/dev/null)
         set -l exit_code $status
         if test $exit_code -eq 0 -a -n "$target" -a -d "$target"
             cd $target
         else
-            command ws home
+            command ws $argv
             return $exit_code
         end
     else if test "$argv[1]" = "new" -a -n "$argv[2]"
@@ -30,18 +29,42 @@ function ws
             end
         end
         return $exit_code
-    else if test "$argv[1]" = "ez" -a -n "$argv[2]"
+    else if test "$argv[1]" = "ez" -o "$argv[1]" = "agent" -o "$argv[1]" = "ui"
+        # ws writes the workspace to cd into, then any agent command to run
+        set -l handoff (mktemp)
+        set -lx WS_HANDOFF $handoff
         command ws $argv
         set -l exit_code $status
-        if test $exit_code -eq 0
-            set -l target (command ws go $argv[2] 2>/dev/null)
-            if test -n "$target" -a -d "$target"
-                cd $target
-                set -l agent_cmd (command ws agent-cmd)
+        set -e WS_HANDOFF
+        set -l target (head -n 1 $handoff)
+        set -l agent_cmd (tail -n +2 $handoff | string collect)
+        rm -f $handoff
+        if test -n "$target" -a -d "$target"
+            cd $target
+            if test $exit_code -eq 0 -a -n "$agent_cmd"
                 eval $agent_cmd
+                command ws hook post-agent
             end
         end
         return $exit_code
+    else if test "$argv[1]" = "fold"
+        command ws $argv
+        set -l exit_code $status
+        # After fold, go home (workspace may be deleted)
+        set -l target (command ws home 2>/dev/null)
+        if test -n "$target" -a -d "$target"
+            cd $target
+        end
+        return $exit_code
+    else if test "$argv[1]" = "auto-rebase"
+        command ws $argv
+        set -l exit_code $status
+        if test $exit_code -eq 0
+            set -l agent_cmd (command ws agent-cmd)
+            eval $agent_cmd "Help me finish this rebase. There are merge conflicts that need to be resolved. Look at the conflicted files, understand both sides, and resolve them appropriately. Ask me any questions if you're unsure about the intent. Once resolved, run 'git add' on the fixed files and 'git rebase --continue'."
+            command ws hook post-agent
+        end
+        return $exit_code
     else
         command ws $argv
     end
@@ -50,13 +73,21 @@ end
 # Completion
 complete -c ws -n "__fish_use_subcommand" -a new -d "Create a new workspace"
 complete -c ws -n "__fish_use_subcommand" -a ez -d "Create workspace and start agent"
+complete -c ws -n "__fish_use_subcommand" -a agent -d "Start agent in a workspace"
 complete -c ws -n "__fish_use_subcommand" -a list -d "List all workspaces"
 complete -c ws -n "__fish_use_subcommand" -a go -d "Navigate to a workspace"
 complete -c ws -n "__fish_use_subcommand" -a home -d "Navigate to main repository"
 complete -c ws -n "__fish_use_subcommand" -a done -d "Remove a workspace"
 complete -c ws -n "__fish_use_subcommand" -a fold -d "Rebase and merge workspace"
+complete -c ws -n "__fish_use_subcommand" -a auto-rebase -d "Resolve rebase conflicts with agent"
 complete -c ws -n "__fish_use_subcommand" -a status -d "Show workspace status"
+complete -c ws -n "__fish_use_subcommand" -a ps -d "List processes in workspaces"
+complete -c ws -n "__fish_use_subcommand" -a kill -d "Stop processes in a workspace"
+complete -c ws -n "__fish_use_subcommand" -a note -d "Attach a note to a workspace"
+complete -c ws -n "__fish_use_subcommand" -a ui -d "Open the workspace dashboard"
+complete -c ws -n "__fish_use_subcommand" -a wait -d "Wait for a workspace to reach a condition"
 complete -c ws -n "__fish_use_subcommand" -a prune -d "Clean up stale worktrees"
 complete -c ws -n "__fish_use_subcommand" -a init -d "Set up shell integration"
+complete -c ws -n "__fish_use_subcommand" -a config -d "Manage configuration"
 
-complete -c ws -n "__fish_seen_subcommand_from go done fold" -a "(command ws list --quiet 2>/dev/null)"
+complete -c ws -n "__fish_seen_subcommand_from go done fold ps kill note wait agent" -a "(command ws list --quiet 2>/dev/null)"